@@ -20,13 +20,15 @@ func main() {
 		log.Fatal(err)
 	}
 
-	//  GCSClient
-	ctx := context.Background()
-	gcsClient, err := storage.NewClient(ctx)
-	if err != nil {
-		log.Fatal(err)
+	//  GCSClient, only when a backend stores data in GCS so local runs need no GCP credentials
+	var gcsClient *storage.Client
+	if cfg.UsesGCS() {
+		gcsClient, err = storage.NewClient(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer gcsClient.Close()
 	}
-	defer gcsClient.Close()
 
 	// create registry from registry.go
 	reg, err := registry.NewRegistry(cfg, gcsClient)
@@ -1,41 +1,275 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	AppPort            string
-	BaseCurrency       string
-	TargetCurrency     string
-	APIProvider        string // "frankfurter" or "exchangerates"
-	ExchangeRateAPIKey string
-	ExchangeRateAPIURL string
-	FrankfurterAPIURL  string
-	SlackWebhookURL    string
-	GCSBucketName      string
-	GCSObjectName      string
+	AppPort                  string
+	BaseCurrency             string
+	TargetCurrency           string
+	APIProvider              string   // "frankfurter", "exchangerates", "ecb", "bankofcanada", "file" or "command"
+	APIProviders             []string // ordered failover chain; defaults to [APIProvider]
+	APIStrategy              string   // how APIProviders are combined: "failover" or "consensus"
+	ConsensusTolerance       float64  // maximum relative spread accepted by the consensus strategy
+	CacheBackend             string   // "memory", "gcs" or "none"
+	CacheTTL                 time.Duration
+	CacheGCSObjectName       string
+	RetryMaxAttempts         int                // attempts per outbound call to providers and Slack
+	RetryBaseDelay           time.Duration      // first backoff delay, doubled on each retry
+	RetryMaxDelay            time.Duration      // cap for a single backoff delay and Retry-After
+	RetryJitter              float64            // random spread of each delay, e.g. 0.2 for ±20%
+	BreakerThreshold         int                // consecutive provider failures that open the circuit; 0 disables
+	BreakerCooldown          time.Duration      // how long an open circuit fails fast before a trial call
+	DecimalScale             int                // decimals kept for derived rates such as cross rates
+	DecimalRounding          string             // "half-even", "half-up", "down" or "up"
+	TriangulationPivots      []string           // currencies tried, in order, to derive pairs a provider does not quote; empty disables pivot derivation
+	ProviderQuotas           map[string]float64 // monthly call limit per provider name
+	ProviderRateLimits       map[string]float64 // outbound calls per second per provider name
+	QuotaWarnRatio           float64            // share of a quota used before the notifier warns
+	UsageBackend             string             // where monthly usage is counted: "memory" or "gcs"
+	UsageGCSObjectName       string
+	ExchangeRateAPIKey       string
+	ExchangeRateAPIURL       string
+	FrankfurterAPIURL        string
+	ECBAPIURL                string        // directory serving eurofxref-daily.xml and eurofxref-hist.xml
+	ValetAPIURL              string        // Bank of Canada Valet API base URL
+	RateFilePath             string        // CSV or JSON file read by the "file" provider
+	RateCommand              string        // executable run by the "command" provider
+	RateCommandInput         string        // "args" or "json" (on stdin)
+	RateCommandTimeout       time.Duration // per run of RateCommand
+	RateCommandMaxBytes      int64         // stdout bytes accepted from RateCommand
+	OutboundProxyURL         string        // proxy for every outbound call; empty uses HTTP(S)_PROXY
+	OutboundCABundle         string        // extra PEM CA certificates for outbound TLS
+	OutboundUserAgent        string
+	OutboundTLSMinVersion    string // "1.2" or "1.3"
+	OutboundMaxIdleConns     int
+	OutboundMaxIdlePerHost   int
+	OutboundMaxConnsPerHost  int // 0 means unlimited
+	OutboundIdleConnTimeout  time.Duration
+	OutboundMaxResponseBytes int64 // larger response bodies are rejected; 0 means unlimited
+	SlackWebhookURL          string
+	StorageBackend           string // where rate history is kept: "gcs", "file", "s3" or "bolt"
+	StorageFilePath          string // history file of the "file" backend
+	StorageBoltPath          string // database file of the "bolt" backend
+	HistoryLimit             int    // days of rates kept per pair; 0 keeps everything
+	S3Endpoint               string // S3-compatible endpoint, e.g. http://localhost:9000 for MinIO
+	S3Region                 string
+	S3Bucket                 string
+	S3Key                    string // object key of the rate history
+	S3PathStyle              bool   // endpoint/bucket/key addressing, needed by most S3 stand-ins
+	S3AccessKeyID            string
+	S3SecretAccessKey        string
+	S3SessionToken           string
+	GCSBucketName            string
+	GCSObjectName            string
+	APITimeout               time.Duration // timeout for each outbound rate provider call
+}
+
+// UsesGCS reports whether any configured backend needs a GCS client
+func (c *Config) UsesGCS() bool {
+	return c.StorageBackend == "gcs" || c.CacheBackend == "gcs" || c.UsageBackend == "gcs"
 }
 
 func Load() (*Config, error) {
 	// load the config from the environment variables
 	_ = godotenv.Load()
 
+	apiTimeout, err := time.ParseDuration(getEnv("API_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_TIMEOUT: %w", err)
+	}
+
+	consensusTolerance, err := strconv.ParseFloat(getEnv("CONSENSUS_TOLERANCE", "0.01"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSENSUS_TOLERANCE: %w", err)
+	}
+
+	cacheTTL, err := time.ParseDuration(getEnv("CACHE_TTL", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CACHE_TTL: %w", err)
+	}
+
+	retryMaxAttempts, err := strconv.Atoi(getEnv("RETRY_MAX_ATTEMPTS", "3"))
+	if err == nil && retryMaxAttempts < 1 {
+		err = fmt.Errorf("%d is less than 1", retryMaxAttempts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETRY_MAX_ATTEMPTS: %w", err)
+	}
+	retryBaseDelay, err := time.ParseDuration(getEnv("RETRY_BASE_DELAY", "500ms"))
+	if err == nil && retryBaseDelay < 0 {
+		err = fmt.Errorf("%s is negative", retryBaseDelay)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETRY_BASE_DELAY: %w", err)
+	}
+	retryMaxDelay, err := time.ParseDuration(getEnv("RETRY_MAX_DELAY", "5s"))
+	if err == nil && retryMaxDelay < 0 {
+		err = fmt.Errorf("%s is negative", retryMaxDelay)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETRY_MAX_DELAY: %w", err)
+	}
+	retryJitter, err := strconv.ParseFloat(getEnv("RETRY_JITTER", "0.2"), 64)
+	if err == nil && (retryJitter < 0 || retryJitter > 1) {
+		err = fmt.Errorf("%g is not between 0 and 1", retryJitter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid RETRY_JITTER: %w", err)
+	}
+
+	breakerThreshold, err := strconv.Atoi(getEnv("BREAKER_FAILURE_THRESHOLD", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BREAKER_FAILURE_THRESHOLD: %w", err)
+	}
+	breakerCooldown, err := time.ParseDuration(getEnv("BREAKER_COOLDOWN", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BREAKER_COOLDOWN: %w", err)
+	}
+
+	decimalScale, err := strconv.Atoi(getEnv("DECIMAL_SCALE", "8"))
+	if err == nil && (decimalScale < 0 || decimalScale > 18) {
+		// a Decimal holds at most 18 significant digits
+		err = fmt.Errorf("%d is not between 0 and 18", decimalScale)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid DECIMAL_SCALE: %w", err)
+	}
+
+	rateCommandTimeout, err := time.ParseDuration(getEnv("RATE_COMMAND_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_COMMAND_TIMEOUT: %w", err)
+	}
+	rateCommandMaxBytes, err := strconv.ParseInt(getEnv("RATE_COMMAND_MAX_BYTES", "65536"), 10, 64)
+	if err == nil && rateCommandMaxBytes <= 0 {
+		err = fmt.Errorf("%d is not positive", rateCommandMaxBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_COMMAND_MAX_BYTES: %w", err)
+	}
+
+	providerQuotas, err := parseProviderValues(getEnv("PROVIDER_MONTHLY_QUOTAS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROVIDER_MONTHLY_QUOTAS: %w", err)
+	}
+	providerRateLimits, err := parseProviderValues(getEnv("PROVIDER_RATE_LIMITS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROVIDER_RATE_LIMITS: %w", err)
+	}
+	quotaWarnRatio, err := strconv.ParseFloat(getEnv("QUOTA_WARN_RATIO", "0.8"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUOTA_WARN_RATIO: %w", err)
+	}
+
+	outboundMaxIdleConns, err := strconv.Atoi(getEnv("OUTBOUND_MAX_IDLE_CONNS", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_IDLE_CONNS: %w", err)
+	}
+	outboundMaxIdlePerHost, err := strconv.Atoi(getEnv("OUTBOUND_MAX_IDLE_CONNS_PER_HOST", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_IDLE_CONNS_PER_HOST: %w", err)
+	}
+	outboundMaxConnsPerHost, err := strconv.Atoi(getEnv("OUTBOUND_MAX_CONNS_PER_HOST", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_CONNS_PER_HOST: %w", err)
+	}
+	outboundIdleConnTimeout, err := time.ParseDuration(getEnv("OUTBOUND_IDLE_CONN_TIMEOUT", "90s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_IDLE_CONN_TIMEOUT: %w", err)
+	}
+	outboundMaxResponseBytes, err := strconv.ParseInt(getEnv("OUTBOUND_MAX_RESPONSE_BYTES", "33554432"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OUTBOUND_MAX_RESPONSE_BYTES: %w", err)
+	}
+
+	s3PathStyle, err := strconv.ParseBool(getEnv("S3_PATH_STYLE", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_PATH_STYLE: %w", err)
+	}
+
+	// the bolt backend only writes the dates that changed, so it keeps every day by default
+	defaultHistoryLimit := "7"
+	if getEnv("STORAGE_BACKEND", "gcs") == "bolt" {
+		defaultHistoryLimit = "0"
+	}
+	historyLimit, err := strconv.Atoi(getEnv("HISTORY_LIMIT", defaultHistoryLimit))
+	if err == nil && historyLimit < 0 {
+		err = fmt.Errorf("%d is negative", historyLimit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid HISTORY_LIMIT: %w", err)
+	}
+
 	cfg := &Config{
 		// Cloud Run sets PORT, but we also support APP_PORT for local dev
-		AppPort:            getEnv("PORT", getEnv("APP_PORT", "8080")),
-		BaseCurrency:       getEnv("BASE_CURRENCY", "CAD"),
-		TargetCurrency:     getEnv("TARGET_CURRENCY", "JPY"),
-		APIProvider:        getEnv("API_PROVIDER", "frankfurter"), // Default to frankfurter (free, no API key)
-		ExchangeRateAPIKey: getEnv("EXCHANGE_RATE_API_KEY", ""),
-		ExchangeRateAPIURL: getEnv("EXCHANGE_RATE_API_URL", ""),
-		FrankfurterAPIURL:  getEnv("FRANKFURTER_API_URL", "https://api.frankfurter.app/"),
-		SlackWebhookURL:    getEnv("SLACK_WEBHOOK_URL", ""),
-		GCSBucketName:      getEnv("GCS_BUCKET_NAME", ""),
-		GCSObjectName:      getEnv("GCS_OBJECT_NAME", ""),
+		AppPort:                  getEnv("PORT", getEnv("APP_PORT", "8080")),
+		BaseCurrency:             getEnv("BASE_CURRENCY", "CAD"),
+		TargetCurrency:           getEnv("TARGET_CURRENCY", "JPY"),
+		APIProvider:              getEnv("API_PROVIDER", "frankfurter"), // Default to frankfurter (free, no API key)
+		ExchangeRateAPIKey:       getEnv("EXCHANGE_RATE_API_KEY", ""),
+		ExchangeRateAPIURL:       getEnv("EXCHANGE_RATE_API_URL", ""),
+		FrankfurterAPIURL:        getEnv("FRANKFURTER_API_URL", "https://api.frankfurter.app/"),
+		ECBAPIURL:                getEnv("ECB_API_URL", "https://www.ecb.europa.eu/stats/eurofxref/"),
+		ValetAPIURL:              getEnv("VALET_API_URL", "https://www.bankofcanada.ca/valet/"),
+		RateFilePath:             getEnv("RATE_FILE_PATH", "rates.csv"),
+		RateCommand:              getEnv("RATE_COMMAND", ""),
+		RateCommandInput:         getEnv("RATE_COMMAND_INPUT", "args"),
+		RateCommandTimeout:       rateCommandTimeout,
+		RateCommandMaxBytes:      rateCommandMaxBytes,
+		OutboundProxyURL:         getEnv("OUTBOUND_PROXY_URL", ""),
+		OutboundCABundle:         getEnv("OUTBOUND_CA_BUNDLE", ""),
+		OutboundUserAgent:        getEnv("OUTBOUND_USER_AGENT", "yenup"),
+		OutboundTLSMinVersion:    getEnv("OUTBOUND_TLS_MIN_VERSION", "1.2"),
+		OutboundMaxIdleConns:     outboundMaxIdleConns,
+		OutboundMaxIdlePerHost:   outboundMaxIdlePerHost,
+		OutboundMaxConnsPerHost:  outboundMaxConnsPerHost,
+		OutboundIdleConnTimeout:  outboundIdleConnTimeout,
+		OutboundMaxResponseBytes: outboundMaxResponseBytes,
+		SlackWebhookURL:          getEnv("SLACK_WEBHOOK_URL", ""),
+		StorageBackend:           getEnv("STORAGE_BACKEND", "gcs"),
+		StorageFilePath:          getEnv("STORAGE_FILE_PATH", "data/rate-history.json"),
+		StorageBoltPath:          getEnv("STORAGE_BOLT_PATH", "data/rate-history.db"),
+		HistoryLimit:             historyLimit,
+		S3Endpoint:               getEnv("S3_ENDPOINT", "https://s3.us-east-1.amazonaws.com"),
+		S3Region:                 getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:                 getEnv("S3_BUCKET", ""),
+		S3Key:                    getEnv("S3_KEY", "rate-history.json"),
+		S3PathStyle:              s3PathStyle,
+		S3AccessKeyID:            getEnv("S3_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+		S3SecretAccessKey:        getEnv("S3_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		S3SessionToken:           getEnv("S3_SESSION_TOKEN", os.Getenv("AWS_SESSION_TOKEN")),
+		GCSBucketName:            getEnv("GCS_BUCKET_NAME", ""),
+		GCSObjectName:            getEnv("GCS_OBJECT_NAME", ""),
+		APITimeout:               apiTimeout,
+		APIStrategy:              getEnv("API_STRATEGY", "failover"),
+		ConsensusTolerance:       consensusTolerance,
+		TriangulationPivots:      splitList(getEnv("TRIANGULATION_PIVOTS", "")),
+		CacheBackend:             getEnv("CACHE_BACKEND", "memory"),
+		CacheTTL:                 cacheTTL,
+		CacheGCSObjectName:       getEnv("CACHE_GCS_OBJECT_NAME", "rate-cache.json"),
+		RetryMaxAttempts:         retryMaxAttempts,
+		RetryBaseDelay:           retryBaseDelay,
+		RetryMaxDelay:            retryMaxDelay,
+		RetryJitter:              retryJitter,
+		BreakerThreshold:         breakerThreshold,
+		BreakerCooldown:          breakerCooldown,
+		DecimalScale:             decimalScale,
+		DecimalRounding:          getEnv("DECIMAL_ROUNDING", "half-even"),
+		ProviderQuotas:           providerQuotas,
+		ProviderRateLimits:       providerRateLimits,
+		QuotaWarnRatio:           quotaWarnRatio,
+		UsageBackend:             getEnv("USAGE_BACKEND", "memory"),
+		UsageGCSObjectName:       getEnv("USAGE_GCS_OBJECT_NAME", "provider-usage.json"),
 	}
+	cfg.APIProviders = splitList(getEnv("API_PROVIDERS", cfg.APIProvider))
+
 	return cfg, nil
 }
 
@@ -46,3 +280,31 @@ func getEnv(key string, fallback string) string {
 	}
 	return fallback
 }
+
+func splitList(value string) []string {
+	// split a comma-separated value, trimming spaces and dropping empty items
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseProviderValues(value string) (map[string]float64, error) {
+	// parse "name=value,name=value" into a map, e.g. "exchangerates=250"
+	values := make(map[string]float64)
+	for _, item := range splitList(value) {
+		name, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=value, got %q", item)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.TrimSpace(name), err)
+		}
+		values[strings.TrimSpace(name)] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,24 @@
+package rate
+
+import (
+	"context"
+	"time"
+)
+
+// CacheEntry is a cached rate with its expiry. A zero ExpiresAt never expires.
+type CacheEntry struct {
+	Rate      Rate      `json:"rate"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired reports whether the entry is no longer valid at now
+func (e CacheEntry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+type Cache interface {
+	// Get the cached entry for key; the bool is false when nothing is cached
+	Get(ctx context.Context, key string) (CacheEntry, bool, error)
+	// Set stores the entry under key
+	Set(ctx context.Context, key string, entry CacheEntry) error
+}
@@ -0,0 +1,86 @@
+package rate
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 alphabetic currency code such as "CAD" or "JPY".
+// Use ParseCurrency to build one from user or provider input.
+type Currency string
+
+// CurrencyInfo is the ISO 4217 table entry of a currency
+type CurrencyInfo struct {
+	Code       Currency
+	Numeric    string // three-digit numeric code, e.g. "392" for JPY
+	MinorUnits int    // digits after the decimal point, e.g. 0 for JPY
+	Name       string
+	Symbol     string
+}
+
+//go:embed iso4217.csv
+var iso4217CSV string
+
+// currencies is the ISO 4217 table keyed by alphabetic code
+var currencies = loadCurrencies(iso4217CSV)
+
+func loadCurrencies(data string) map[Currency]CurrencyInfo {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded ISO 4217 table: %v", err))
+	}
+
+	table := make(map[Currency]CurrencyInfo, len(records))
+	for _, rec := range records[1:] { // skip header
+		minor, err := strconv.Atoi(rec[2])
+		if err != nil {
+			panic(fmt.Sprintf("invalid minor units for %s: %v", rec[0], err))
+		}
+		table[Currency(rec[0])] = CurrencyInfo{
+			Code:       Currency(rec[0]),
+			Numeric:    rec[1],
+			MinorUnits: minor,
+			Name:       rec[3],
+			Symbol:     rec[4],
+		}
+	}
+	return table
+}
+
+// ParseCurrency parses a case-insensitive ISO 4217 code.
+// Unknown codes return an error wrapping ErrInvalidCurrency.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := currencies[c]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCurrency, code)
+	}
+	return c, nil
+}
+
+// Info returns the ISO 4217 table entry; ok is false for unknown codes
+func (c Currency) Info() (CurrencyInfo, bool) {
+	info, ok := currencies[c]
+	return info, ok
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// UnmarshalText validates the code so stored and provider data cannot carry unknown currencies
+func (c *Currency) UnmarshalText(text []byte) error {
+	parsed, err := ParseCurrency(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// MarshalText keeps the JSON form a plain code string
+func (c Currency) MarshalText() ([]byte, error) {
+	return []byte(c), nil
+}
@@ -0,0 +1,86 @@
+package rate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Currency
+		wantErr bool
+	}{
+		{name: "success: upper case", input: "JPY", want: "JPY"},
+		{name: "success: lower case with spaces", input: " cad ", want: "CAD"},
+		{name: "error: typo", input: "JYP", wantErr: true},
+		{name: "error: empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCurrency(tt.input)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCurrency)
+				assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCurrencyInfo(t *testing.T) {
+	info, ok := Currency("JPY").Info()
+
+	assert.True(t, ok)
+	assert.Equal(t, CurrencyInfo{Code: "JPY", Numeric: "392", MinorUnits: 0, Name: "Yen", Symbol: "¥"}, info)
+}
+
+func TestRateJSON(t *testing.T) {
+	var r Rate
+	err := json.Unmarshal([]byte(`{"date":"2026-03-19","base":"cad","target":"JPY","value":110.22}`), &r)
+	assert.NoError(t, err)
+	assert.Equal(t, Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: MustParseDecimal("110.22")}, r)
+
+	data, err := json.Marshal(r)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"date":"2026-03-19","base":"CAD","target":"JPY","value":110.22}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"date":"2026-03-19","base":"CAD","target":"JYP","value":110.22}`), &r)
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestParsePair(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Pair
+		wantErr bool
+	}{
+		{name: "success", input: "CAD/JPY", want: Pair{Base: "CAD", Target: "JPY"}},
+		{name: "success: lower case", input: "usd/jpy", want: Pair{Base: "USD", Target: "JPY"}},
+		{name: "error: no separator", input: "CADJPY", wantErr: true},
+		{name: "error: unknown currency", input: "CAD/JYP", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePair(tt.input)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCurrency)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Equal(t, strings.ToUpper(tt.input), got.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package rate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact fixed-point number: coef / 10^scale.
+// Values are kept canonical (no trailing zeros in coef) so equal numbers compare equal with ==.
+// The zero value is 0.
+//
+// Decimal is meant for exchange rates and money amounts; magnitudes that do not fit
+// in 18 significant digits lose their least significant decimals, and arithmetic on
+// integer parts beyond int64 fails with ErrDecimalOverflow.
+type Decimal struct {
+	coef  int64
+	scale int32
+}
+
+// RoundingMode selects how digits beyond the target scale are dropped
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // to nearest, ties to even (banker's rounding)
+	RoundHalfUp                       // to nearest, ties away from zero
+	RoundDown                         // towards zero (truncate)
+	RoundUp                           // away from zero
+)
+
+// Precision is the scale and rounding mode applied to results that are not exact,
+// such as cross rates and averages
+type Precision struct {
+	Scale int32
+	Mode  RoundingMode
+}
+
+// DefaultPrecision keeps 8 decimals with banker's rounding, more than any provider publishes
+var DefaultPrecision = Precision{Scale: 8, Mode: RoundHalfEven}
+
+// ErrDecimalOverflow means a number does not fit in a Decimal
+var ErrDecimalOverflow = errors.New("decimal overflow")
+
+var (
+	bigTen   = big.NewInt(10)
+	minInt64 = big.NewInt(-1 << 63)
+	maxInt64 = big.NewInt(1<<63 - 1)
+)
+
+// ParseRoundingMode parses "half-even", "half-up", "down" or "up"
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "half-even":
+		return RoundHalfEven, nil
+	case "half-up":
+		return RoundHalfUp, nil
+	case "down":
+		return RoundDown, nil
+	case "up":
+		return RoundUp, nil
+	default:
+		return 0, fmt.Errorf("unknown rounding mode: %q", s)
+	}
+}
+
+// ParseDecimal parses a decimal number such as "110.2207", "-0.5" or "1.25e-4"
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q: %w", s, err)
+		}
+		if exp < -64 || exp > 64 {
+			return Decimal{}, fmt.Errorf("invalid decimal %q: %w", s, ErrDecimalOverflow)
+		}
+		mantissa = s[:i]
+	}
+
+	digits, scale := mantissa, int64(0)
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		scale = int64(len(mantissa) - i - 1)
+	}
+	unsigned := strings.TrimLeft(digits, "+-")
+	if unsigned == "" || len(digits)-len(unsigned) > 1 || strings.ContainsAny(unsigned, "+-") {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	scale -= exp
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+
+	d, err := fromBig(coef, int32(scale))
+	if err != nil {
+		return Decimal{}, fmt.Errorf("invalid decimal %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input.
+// It is intended for constants and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimalFromInt returns the Decimal for an integer
+func NewDecimalFromInt(i int64) Decimal {
+	return canonical(i, 0)
+}
+
+// NewDecimalFromFloat returns the shortest Decimal that round-trips to f
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+// Sign returns -1, 0 or +1
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether d is less than, equal to or greater than o
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+// LessThan reports whether d < o
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+// GreaterThan reports whether d > o
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	a, b := align(d, o)
+	return fromBig(a.Add(a, b), max(d.scale, o.scale))
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) (Decimal, error) {
+	a, b := align(d, o)
+	return fromBig(a.Sub(a, b), max(d.scale, o.scale))
+}
+
+// Mul returns d × o
+func (d Decimal) Mul(o Decimal) (Decimal, error) {
+	c := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(o.coef))
+	return fromBig(c, d.scale+o.scale)
+}
+
+// Div returns d ÷ o rounded to p
+func (d Decimal) Div(o Decimal, p Precision) (Decimal, error) {
+	if o.IsZero() {
+		return Decimal{}, errors.New("decimal division by zero")
+	}
+	// d/o = d.coef·10^(o.scale+p.Scale) / (o.coef·10^d.scale), in units of 10^-p.Scale
+	num := new(big.Int).Mul(big.NewInt(d.coef), pow10(o.scale+p.Scale))
+	den := new(big.Int).Mul(big.NewInt(o.coef), pow10(d.scale))
+	return fromBig(quoRound(num, den, p.Mode), p.Scale)
+}
+
+// Round returns d rounded to scale decimals
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+	// dividing by at least 10 keeps the coefficient well inside int64
+	c := quoRound(big.NewInt(d.coef), pow10(d.scale-scale), mode)
+	return canonical(c.Int64(), scale)
+}
+
+// Float64 returns the nearest float64, for statistics and display only
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain decimal notation, e.g. "110.2207"
+func (d Decimal) String() string {
+	return d.StringFixed(d.scale)
+}
+
+// StringFixed returns d rounded half-even to scale decimals, padding with zeros
+func (d Decimal) StringFixed(scale int32) string {
+	r := d.Round(scale, RoundHalfEven)
+	digits := strconv.FormatInt(r.coef, 10)
+	neg := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	if pad := int(r.scale) - len(digits) + 1; pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-int(r.scale)], digits[len(digits)-int(r.scale):]
+	fracPart += strings.Repeat("0", int(scale-r.scale))
+
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// Format implements fmt.Formatter so %f and %.4f print the exact value.
+// Other float verbs fall back to float64 formatting.
+func (d Decimal) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'f', 'F':
+		if prec, ok := f.Precision(); ok {
+			fmt.Fprint(f, d.StringFixed(int32(prec)))
+			return
+		}
+		fmt.Fprint(f, d.String())
+	case 'v', 's':
+		fmt.Fprint(f, d.String())
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), d.Float64())
+	}
+}
+
+// MarshalJSON writes d as a JSON number, compatible with the former float64 values
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string without going through float64
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// align returns the coefficients of a and b at their common scale
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	scale := max(a.scale, b.scale)
+	x := new(big.Int).Mul(big.NewInt(a.coef), pow10(scale-a.scale))
+	y := new(big.Int).Mul(big.NewInt(b.coef), pow10(scale-b.scale))
+	return x, y
+}
+
+// fromBig builds a canonical Decimal, dropping least significant decimals (half-even)
+// when the coefficient does not fit in int64
+func fromBig(c *big.Int, scale int32) (Decimal, error) {
+	for c.Cmp(minInt64) < 0 || c.Cmp(maxInt64) > 0 {
+		if scale == 0 {
+			return Decimal{}, ErrDecimalOverflow
+		}
+		c = quoRound(c, bigTen, RoundHalfEven)
+		scale--
+	}
+	return canonical(c.Int64(), scale), nil
+}
+
+// canonical strips trailing zeros so every value has exactly one representation
+func canonical(coef int64, scale int32) Decimal {
+	if coef == 0 {
+		return Decimal{}
+	}
+	for scale > 0 && coef%10 == 0 {
+		coef /= 10
+		scale--
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// quoRound returns num/den rounded to an integer with mode
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundHalfUp, RoundHalfEven:
+		// compare |2r| with |den|
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		switch twice.Cmp(new(big.Int).Abs(den)) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
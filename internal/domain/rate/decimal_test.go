@@ -0,0 +1,149 @@
+package rate
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "110.2207", want: "110.2207"},
+		{input: "110.2200", want: "110.22"},
+		{input: "-0.5", want: "-0.5"},
+		{input: "1.25e-4", want: "0.000125"},
+		{input: "2E3", want: "2000"},
+		{input: "0.000", want: "0"},
+		{input: "", wantErr: true},
+		{input: "1.2.3", wantErr: true},
+		{input: "--1", wantErr: true},
+		{input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDecimal(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+
+	must := func(v Decimal, err error) Decimal {
+		assert.NoError(t, err)
+		return v
+	}
+
+	// 0.1 + 0.2 is exact, unlike float64
+	assert.Equal(t, d("0.3"), must(d("0.1").Add(d("0.2"))))
+	assert.Equal(t, d("-0.1"), must(d("0.1").Sub(d("0.2"))))
+	assert.Equal(t, d("12.5"), must(d("2.5").Mul(d("5"))))
+	assert.True(t, d("110.22").LessThan(d("110.2201")))
+	assert.Equal(t, 0, d("1.10").Cmp(d("1.1")))
+
+	// EUR/JPY ÷ EUR/CAD cross rate
+	cross, err := d("165.43").Div(d("1.4921"), Precision{Scale: 4, Mode: RoundHalfEven})
+	assert.NoError(t, err)
+	assert.Equal(t, d("110.8706"), cross)
+
+	_, err = d("1").Div(Decimal{}, DefaultPrecision)
+	assert.Error(t, err)
+
+	// integer parts beyond int64 are an error, not a panic
+	huge := NewDecimalFromInt(1 << 62)
+	_, err = huge.Add(huge)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+	_, err = NewDecimalFromInt(-1<<62 - 1).Sub(huge)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+	_, err = huge.Mul(d("4"))
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+}
+
+func TestDecimalRound(t *testing.T) {
+	d := MustParseDecimal
+
+	tests := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{value: "2.345", mode: RoundHalfEven, want: "2.34"},
+		{value: "2.355", mode: RoundHalfEven, want: "2.36"},
+		{value: "2.345", mode: RoundHalfUp, want: "2.35"},
+		{value: "-2.345", mode: RoundHalfUp, want: "-2.35"},
+		{value: "2.349", mode: RoundDown, want: "2.34"},
+		{value: "2.341", mode: RoundUp, want: "2.35"},
+		{value: "-2.341", mode: RoundUp, want: "-2.35"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, d(tt.want), d(tt.value).Round(2, tt.mode))
+		})
+	}
+}
+
+func TestDecimalFormatAndJSON(t *testing.T) {
+	v := MustParseDecimal("110.22")
+
+	assert.Equal(t, "110.2200", fmt.Sprintf("%.4f", v))
+	assert.Equal(t, "110.22", fmt.Sprintf("%v", v))
+	assert.Equal(t, "0.05", MustParseDecimal("0.05").String())
+
+	data, err := json.Marshal(struct {
+		Value Decimal `json:"value"`
+	}{v})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"value":110.22}`, string(data))
+
+	var parsed struct {
+		Value Decimal `json:"value"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"value":112.57830000000001}`), &parsed))
+	assert.Equal(t, "112.57830000000001", parsed.Value.String())
+	assert.NoError(t, json.Unmarshal([]byte(`{"value":"0.0071"}`), &parsed))
+	assert.Equal(t, MustParseDecimal("0.0071"), parsed.Value)
+}
+
+func TestRateConvert(t *testing.T) {
+	r := Rate{Base: "CAD", Target: "JPY", Value: MustParseDecimal("110.2207")}
+
+	tests := []struct {
+		amount  string
+		mode    RoundingMode
+		want    string
+		wantErr error
+	}{
+		// JPY has no minor units
+		{amount: "100", mode: RoundHalfEven, want: "11022"},
+		{amount: "12.50", mode: RoundHalfUp, want: "1378"},
+		{amount: "100000000000000000", mode: RoundHalfEven, wantErr: ErrDecimalOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := r.Convert(MustParseDecimal(tt.amount), tt.mode)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.want), got)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package rate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned (wrapped) by RateFetcher implementations.
+// Callers should match them with errors.Is.
+var (
+	// ErrRateNotFound means the provider has no rate for the requested date.
+	ErrRateNotFound = errors.New("rate not found")
+	// ErrUnsupportedCurrency means the provider does not know the base or target currency.
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrProviderUnavailable means the provider could not be reached or failed on its side.
+	ErrProviderUnavailable = errors.New("rate provider unavailable")
+	// ErrQuotaExceeded means the provider rejected the call because of rate limits or plan quota.
+	ErrQuotaExceeded = errors.New("rate provider quota exceeded")
+	// ErrQuotaUsedUp means the local usage meter refused the call before it reached the provider.
+	// It is a kind of ErrQuotaExceeded.
+	ErrQuotaUsedUp = fmt.Errorf("%w: monthly quota used up", ErrQuotaExceeded)
+	// ErrMalformedResponse means the provider answered with a body that could not be understood.
+	ErrMalformedResponse = errors.New("malformed provider response")
+	// ErrInvalidCurrency means a code is not in the ISO 4217 table.
+	// It is a kind of ErrUnsupportedCurrency.
+	ErrInvalidCurrency = fmt.Errorf("%w: not an ISO 4217 code", ErrUnsupportedCurrency)
+	// ErrCircuitOpen means calls to the provider are suspended after repeated failures.
+	// It is a kind of ErrProviderUnavailable.
+	ErrCircuitOpen = fmt.Errorf("%w: circuit open", ErrProviderUnavailable)
+)
+
+// IsRetryable reports whether err is a provider-side failure that another attempt
+// or another provider may not run into.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrProviderUnavailable) ||
+		errors.Is(err, ErrQuotaExceeded) ||
+		errors.Is(err, ErrMalformedResponse)
+}
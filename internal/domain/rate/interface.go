@@ -1,6 +1,45 @@
 package rate
 
+import "context"
+
 type RateFetcher interface {
 	// Get the rate for a given base and target currency
-	FetchRate(date, base, target string) (Rate, error)
+	FetchRate(ctx context.Context, date string, base, target Currency) (Rate, error)
+}
+
+// RangeFetcher is implemented by providers that can return a time series in one call
+type RangeFetcher interface {
+	// Get the rates for every available date between from and to (inclusive), oldest first
+	FetchRange(ctx context.Context, from, to string, base, target Currency) ([]Rate, error)
+}
+
+// BatchFetcher is implemented by providers that can return several targets for one base in one call
+type BatchFetcher interface {
+	// Get the rates for a given base and each target currency, in the order of targets
+	FetchRates(ctx context.Context, date string, base Currency, targets []Currency) ([]Rate, error)
+}
+
+// Circuit breaker states reported in ProviderStatus.State
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// ProviderStatus is the health of a single rate provider
+type ProviderStatus struct {
+	Name     string
+	State    string // one of the Breaker* states
+	Failures int    // consecutive failures counted towards tripping
+}
+
+// StatusReporter is implemented by fetchers that track the health of their provider
+type StatusReporter interface {
+	Status() ProviderStatus
+}
+
+// CurrencyLister is implemented by providers that can report which currencies they quote
+type CurrencyLister interface {
+	// Get the supported currency codes, sorted
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 }
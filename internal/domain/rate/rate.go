@@ -1,8 +1,68 @@
 package rate
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Rate struct {
-	Date   string  `json:"date"`
-	Base   string  `json:"base"`
-	Target string  `json:"target"`
-	Value  float64 `json:"value"`
+	EffectiveDate string   `json:"date"`                     // day the provider published the value for
+	RequestedDate string   `json:"requested_date,omitempty"` // day that was asked for; later than EffectiveDate when the market was closed
+	Base          Currency `json:"base"`
+	Target        Currency `json:"target"`
+	Value         Decimal  `json:"value"`
+	Provider      string   `json:"provider,omitempty"` // name of the provider that answered
+
+	// Set when the pair was not quoted directly but computed from other quotes
+	Derived bool     `json:"derived,omitempty"` // inverted or triangulated
+	Pivot   Currency `json:"pivot,omitempty"`   // intermediate currency; empty for a plain inversion
+
+	// Set by fetchers that cross-check several providers
+	Spread      float64 `json:"spread,omitempty"`      // maximum relative spread between providers
+	Divergent   bool    `json:"divergent,omitempty"`   // spread exceeded the configured tolerance
+	Unconfirmed bool    `json:"unconfirmed,omitempty"` // only one provider answered, so nothing was cross-checked
+}
+
+// Convert converts amount of Base into Target, rounded to the target's ISO 4217 minor units
+func (r Rate) Convert(amount Decimal, mode RoundingMode) (Decimal, error) {
+	converted, err := amount.Mul(r.Value)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("failed to convert %s %s: %w", amount, r.Base, err)
+	}
+	if info, ok := r.Target.Info(); ok {
+		return converted.Round(int32(info.MinorUnits), mode), nil
+	}
+	return converted, nil
+}
+
+// Pair returns the currency pair the rate quotes
+func (r Rate) Pair() Pair {
+	return Pair{Base: r.Base, Target: r.Target}
+}
+
+// Pair is a base/target currency pair, written as "CAD/JPY"
+type Pair struct {
+	Base   Currency
+	Target Currency
+}
+
+// ParsePair parses a pair written as "BASE/TARGET"
+func ParsePair(s string) (Pair, error) {
+	base, target, ok := strings.Cut(s, "/")
+	if !ok {
+		return Pair{}, fmt.Errorf("%w: pair %q is not BASE/TARGET", ErrInvalidCurrency, s)
+	}
+	b, err := ParseCurrency(base)
+	if err != nil {
+		return Pair{}, err
+	}
+	t, err := ParseCurrency(target)
+	if err != nil {
+		return Pair{}, err
+	}
+	return Pair{Base: b, Target: t}, nil
+}
+
+func (p Pair) String() string {
+	return string(p.Base) + "/" + string(p.Target)
 }
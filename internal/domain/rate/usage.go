@@ -0,0 +1,11 @@
+package rate
+
+import "context"
+
+// UsageStore persists how many calls were made to each provider per calendar month
+type UsageStore interface {
+	// Reserve records n more calls for provider in month (YYYY-MM) and returns the new total.
+	// When limit is positive and the total would exceed it, nothing is recorded and it fails
+	// with ErrQuotaUsedUp, so concurrent callers cannot overrun the quota together.
+	Reserve(ctx context.Context, provider, month string, n, limit int) (int, error)
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"fmt"
+
+	"yenup/internal/domain/rate"
+)
+
+// Version identifies the stored state a history was read at.
+// Zero means nothing was stored yet.
+type Version int64
+
+// ConflictError is returned by Client.Write when the history was changed by another
+// writer since it was read. The caller should read again and redo its change.
+type ConflictError struct {
+	Pair    rate.Pair // for a batch write, the first of its pairs
+	Version Version   // version the caller read
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("rate history of %s changed since version %d", e.Pair, e.Version)
+}
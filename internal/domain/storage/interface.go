@@ -5,9 +5,39 @@ import (
 	"yenup/internal/domain/rate"
 )
 
+// Client stores the rate history of each currency pair separately
 type Client interface {
-	// Read a rate data from storage
-	Read(ctx context.Context) ([]*rate.Rate, error)
-	// Write a rate data to storage
-	Write(ctx context.Context, rates []*rate.Rate) error
+	// Read the rate history of a pair and the version it was read at;
+	// a pair without history returns an empty slice
+	Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, Version, error)
+	// Write the rate history of a pair, leaving other pairs untouched.
+	// It fails with *ConflictError when the stored version is no longer version.
+	Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version Version) error
+	// Pairs lists the pairs that have a history
+	Pairs(ctx context.Context) ([]rate.Pair, error)
+}
+
+// RangeReader is implemented by clients that can query a pair's history by date
+// without loading all of it
+type RangeReader interface {
+	// ReadRange returns the rates of pair effective between from and to (inclusive, YYYY-MM-DD), in date order
+	ReadRange(ctx context.Context, pair rate.Pair, from, to string) ([]*rate.Rate, error)
+}
+
+// BatchClient is implemented by clients that keep every pair in one object, so the histories
+// of several pairs can be read and written in a single round trip each
+type BatchClient interface {
+	// ReadPairs returns the history of each of pairs and the version they were read at
+	ReadPairs(ctx context.Context, pairs []rate.Pair) (map[rate.Pair][]*rate.Rate, Version, error)
+	// WritePairs replaces the history of every pair in histories in one write, leaving other pairs untouched.
+	// It fails with *ConflictError when the stored version is no longer version.
+	WritePairs(ctx context.Context, histories map[rate.Pair][]*rate.Rate, version Version) error
+}
+
+// RatePutter is implemented by clients that can store single dates of a history without
+// reading or rewriting the rest of it
+type RatePutter interface {
+	// PutRates stores each rate under its pair and effective date, replacing a rate stored for
+	// the same date, then drops the oldest dates of each pair beyond limit days (0 keeps all)
+	PutRates(ctx context.Context, rates []*rate.Rate, limit int) error
 }
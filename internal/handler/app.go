@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"yenup/internal/handler/health"
 	"yenup/internal/handler/rate"
 	"yenup/internal/handler/report"
 )
@@ -8,11 +9,13 @@ import (
 type Handler struct {
 	RateHandler   *rate.RateHandler
 	ReportHandler *report.ReportHandler
+	HealthHandler *health.HealthHandler
 }
 
-func NewHandler(rateHandler *rate.RateHandler, reportHandler *report.ReportHandler) *Handler {
+func NewHandler(rateHandler *rate.RateHandler, reportHandler *report.ReportHandler, healthHandler *health.HealthHandler) *Handler {
 	return &Handler{
 		RateHandler:   rateHandler,
 		ReportHandler: reportHandler,
+		HealthHandler: healthHandler,
 	}
 }
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"net/http"
+
+	"yenup/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	Usecase usecase.HealthUsecase
+}
+
+func NewHealthHandler(u usecase.HealthUsecase) *HealthHandler {
+	return &HealthHandler{
+		Usecase: u,
+	}
+}
+
+// ProviderData is the health of one rate provider in the response
+type ProviderData struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+}
+
+// Health reports the circuit breaker state of every rate provider.
+// It answers 503 when all providers are tripped.
+func (h *HealthHandler) Health(c *gin.Context) {
+	result := h.Usecase.CheckHealth(c.Request.Context())
+
+	providers := make([]ProviderData, 0, len(result.Providers))
+	for _, p := range result.Providers {
+		providers = append(providers, ProviderData{
+			Name:     p.Name,
+			State:    p.State,
+			Failures: p.Failures,
+		})
+	}
+
+	status, code := "ok", http.StatusOK
+	if !result.Healthy {
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "providers": providers})
+}
@@ -0,0 +1,30 @@
+package rate
+
+import (
+	"errors"
+	"net/http"
+
+	domainRate "yenup/internal/domain/rate"
+	"yenup/internal/usecase"
+)
+
+// statusFromError maps domain rate errors to HTTP status codes.
+// Anything not recognised is treated as an internal error.
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, domainRate.ErrUnsupportedCurrency), errors.Is(err, usecase.ErrBackfillExceedsHistoryLimit):
+		return http.StatusBadRequest
+	case errors.Is(err, domainRate.ErrRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainRate.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domainRate.ErrMalformedResponse):
+		return http.StatusBadGateway
+	case errors.Is(err, domainRate.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, usecase.ErrCurrencyListUnsupported), errors.Is(err, usecase.ErrBackfillUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
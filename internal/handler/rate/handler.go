@@ -3,7 +3,10 @@ package rate
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	domainRate "yenup/internal/domain/rate"
 	"yenup/internal/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +14,16 @@ import (
 
 // RateHandler is the handler for the rate route
 type RateHandler struct {
-	Usecase usecase.RateCheckUsecase // Changed to interface
+	Usecase    usecase.RateCheckUsecase // Changed to interface
+	Currencies usecase.CurrencyUsecase
+	Rounding   domainRate.RoundingMode // applied to converted amounts
 }
 
 // NewRateHandler creates a new RateHandler
-func NewRateHandler(u usecase.RateCheckUsecase) *RateHandler { // Changed to interface
+func NewRateHandler(u usecase.RateCheckUsecase, currencies usecase.CurrencyUsecase) *RateHandler { // Changed to interface
 	return &RateHandler{
-		Usecase: u,
+		Usecase:    u,
+		Currencies: currencies,
 	}
 }
 
@@ -30,15 +36,29 @@ type Response struct {
 
 // RateData is the data returned in the response
 type RateData struct {
-	Base          string  `json:"base"`
-	Target        string  `json:"target"`
-	TodayRate     float64 `json:"today_rate"`
-	YesterdayRate float64 `json:"yesterday_rate"`
-	Change        string  `json:"change"`
-	IsNotified    bool    `json:"is_notified"`
+	Base          string             `json:"base"`
+	Target        string             `json:"target"`
+	TodayRate     domainRate.Decimal `json:"today_rate"`
+	YesterdayRate domainRate.Decimal `json:"yesterday_rate"`
+	Change        string             `json:"change"`
+	IsNotified    bool               `json:"is_notified"`
+	Provider      string             `json:"provider,omitempty"`
+	Derived       bool               `json:"derived,omitempty"`
+	Pivot         string             `json:"pivot,omitempty"`
+	TodayDate     string             `json:"today_date,omitempty"`
+	YesterdayDate string             `json:"yesterday_date,omitempty"`
+	MarketClosed  bool               `json:"market_closed,omitempty"`
+
+	// Set when an amount was requested: amount of base converted into target at today's rate
+	Amount    *domainRate.Decimal `json:"amount,omitempty"`
+	Converted *domainRate.Decimal `json:"converted,omitempty"`
+
+	Spread             float64 `json:"spread,omitempty"`
+	DataQualityWarning bool    `json:"data_quality_warning,omitempty"`
 }
 
-// CheckRate checks the rate of the base and target currencies
+// CheckRate checks the rate of the base and target currencies.
+// target may be a comma-separated list (e.g. target=JPY,USD) to check several pairs at once.
 func (h *RateHandler) CheckRate(c *gin.Context) {
 	ctx := c.Request.Context()
 	base := c.Query("base")
@@ -46,7 +66,7 @@ func (h *RateHandler) CheckRate(c *gin.Context) {
 	// If notification=true, force sending a Slack message for testing/verification.
 	notificationRaw := c.Query("notification")
 
-	if base == "" || target == "" {
+	if base == "" || strings.Trim(target, ", ") == "" {
 		c.JSON(http.StatusBadRequest, Response{
 			Status:  "error",
 			Message: "base and target are required",
@@ -69,10 +89,34 @@ func (h *RateHandler) CheckRate(c *gin.Context) {
 		forceNotify = parsed
 	}
 
-	// use usecase to check the rate
-	result, err := h.Usecase.CheckRates(ctx, base, target, forceNotify)
+	// amount=100 converts 100 units of base at today's rate in the response
+	var amount *domainRate.Decimal
+	if raw := c.Query("amount"); raw != "" {
+		parsed, err := domainRate.ParseDecimal(raw)
+		if err != nil || parsed.Sign() < 0 {
+			c.JSON(http.StatusBadRequest, Response{
+				Status:  "error",
+				Message: "amount must be a non-negative number",
+				Data:    nil,
+			})
+			return
+		}
+		amount = &parsed
+	}
+
+	// reject unknown ISO 4217 codes before they reach a provider
+	baseCurrency, err := domainRate.ParseCurrency(base)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	targets, err := parseTargets(target)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
+		c.JSON(http.StatusBadRequest, Response{
 			Status:  "error",
 			Message: err.Error(),
 			Data:    nil,
@@ -80,24 +124,254 @@ func (h *RateHandler) CheckRate(c *gin.Context) {
 		return
 	}
 
-	// Determine change direction
-	change := "unchanged"
-	if result.TodayRate < result.YesterdayRate {
-		change = "down (JPY stronger)"
-	} else if result.TodayRate > result.YesterdayRate {
-		change = "up (JPY weaker)"
+	// reject codes the active provider does not offer
+	if err := h.Currencies.ValidateCurrencies(ctx, append([]domainRate.Currency{baseCurrency}, targets...)...); err != nil {
+		c.JSON(statusFromError(err), Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	if len(targets) > 1 {
+		results, err := h.Usecase.CheckMultipleRates(ctx, baseCurrency, targets, forceNotify)
+		if err != nil {
+			c.JSON(statusFromError(err), Response{
+				Status:  "error",
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		data := make([]RateData, 0, len(results))
+		for _, result := range results {
+			rateData, err := h.newRateData(result, amount)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, Response{
+					Status:  "error",
+					Message: err.Error(),
+					Data:    nil,
+				})
+				return
+			}
+			data = append(data, rateData)
+		}
+		c.JSON(http.StatusOK, Response{
+			Status:  "success",
+			Message: "Rate check executed successfully",
+			Data:    data,
+		})
+		return
+	}
+
+	// use usecase to check the rate
+	result, err := h.Usecase.CheckRates(ctx, baseCurrency, targets[0], forceNotify)
+	if err != nil {
+		c.JSON(statusFromError(err), Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
 	}
 
+	data, err := h.newRateData(result, amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
 	c.JSON(http.StatusOK, Response{
 		Status:  "success",
 		Message: "Rate check executed successfully",
-		Data: RateData{
-			Base:          base,
-			Target:        target,
-			TodayRate:     result.TodayRate,
-			YesterdayRate: result.YesterdayRate,
-			Change:        change,
-			IsNotified:    result.IsNotified,
+		Data:    data,
+	})
+}
+
+// CurrencyData is one supported currency in the response
+type CurrencyData struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol,omitempty"`
+}
+
+// ListCurrencies lists the currencies the active rate provider supports
+func (h *RateHandler) ListCurrencies(c *gin.Context) {
+	currencies, err := h.Currencies.ListCurrencies(c.Request.Context())
+	if err != nil {
+		c.JSON(statusFromError(err), Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	data := make([]CurrencyData, 0, len(currencies))
+	for _, currency := range currencies {
+		info, _ := currency.Info()
+		data = append(data, CurrencyData{
+			Code:   currency.String(),
+			Name:   info.Name,
+			Symbol: info.Symbol,
+		})
+	}
+	c.JSON(http.StatusOK, Response{
+		Status:  "success",
+		Message: "Currencies listed successfully",
+		Data:    data,
+	})
+}
+
+// maxBackfillDays bounds the date range of a single backfill request
+const maxBackfillDays = 366
+
+// BackfillData is the result of a history backfill
+type BackfillData struct {
+	Base   string `json:"base"`
+	Target string `json:"target"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Saved  int    `json:"saved"`
+}
+
+// BackfillHistory fetches the rates of base/target between from and to (YYYY-MM-DD,
+// to defaults to today) and merges them into the stored history.
+func (h *RateHandler) BackfillHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	from := c.Query("from")
+	to := c.DefaultQuery("to", time.Now().Format("2006-01-02"))
+
+	badRequest := func(message string) {
+		c.JSON(http.StatusBadRequest, Response{
+			Status:  "error",
+			Message: message,
+			Data:    nil,
+		})
+	}
+
+	base, err := domainRate.ParseCurrency(c.Query("base"))
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	target, err := domainRate.ParseCurrency(c.Query("target"))
+	if err != nil {
+		badRequest(err.Error())
+		return
+	}
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		badRequest("from must be a date (YYYY-MM-DD)")
+		return
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		badRequest("to must be a date (YYYY-MM-DD)")
+		return
+	}
+	if end.Before(start) || end.Sub(start) >= maxBackfillDays*24*time.Hour {
+		badRequest("from must not be after to, and the range must not exceed 366 days")
+		return
+	}
+
+	if err := h.Currencies.ValidateCurrencies(ctx, base, target); err != nil {
+		c.JSON(statusFromError(err), Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	saved, err := h.Usecase.BackfillHistory(ctx, base, target, from, to)
+	if err != nil {
+		c.JSON(statusFromError(err), Response{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Status:  "success",
+		Message: "History backfilled successfully",
+		Data: BackfillData{
+			Base:   base.String(),
+			Target: target.String(),
+			From:   from,
+			To:     to,
+			Saved:  saved,
 		},
 	})
 }
+
+// newRateData converts a usecase result into the response payload, converting amount
+// at today's rate when it is set
+func (h *RateHandler) newRateData(result *usecase.CheckRateResult, amount *domainRate.Decimal) (RateData, error) {
+	// Determine change direction
+	change := "unchanged"
+	if result.TodayRate.LessThan(result.YesterdayRate) {
+		change = "down (JPY stronger)"
+	} else if result.TodayRate.GreaterThan(result.YesterdayRate) {
+		change = "up (JPY weaker)"
+	}
+	if result.MarketClosed {
+		change = "market closed"
+	}
+
+	data := RateData{
+		Base:          result.Base.String(),
+		Target:        result.Target.String(),
+		TodayRate:     result.TodayRate,
+		YesterdayRate: result.YesterdayRate,
+		Change:        change,
+		IsNotified:    result.IsNotified,
+		Provider:      result.Provider,
+		Derived:       result.Derived,
+		Pivot:         result.Pivot.String(),
+		TodayDate:     result.TodayDate,
+		YesterdayDate: result.YesterdayDate,
+		MarketClosed:  result.MarketClosed,
+
+		Spread:             result.Spread,
+		DataQualityWarning: result.DataQualityWarning,
+	}
+
+	if amount != nil {
+		today := domainRate.Rate{Base: result.Base, Target: result.Target, Value: result.TodayRate}
+		converted, err := today.Convert(*amount, h.Rounding)
+		if err != nil {
+			return RateData{}, err
+		}
+		data.Amount, data.Converted = amount, &converted
+	}
+	return data, nil
+}
+
+// parseTargets parses a comma-separated target list, dropping blanks and duplicates
+func parseTargets(raw string) ([]domainRate.Currency, error) {
+	seen := make(map[domainRate.Currency]bool)
+	var targets []domainRate.Currency
+	for _, t := range strings.Split(raw, ",") {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		currency, err := domainRate.ParseCurrency(t)
+		if err != nil {
+			return nil, err
+		}
+		if seen[currency] {
+			continue
+		}
+		seen[currency] = true
+		targets = append(targets, currency)
+	}
+	return targets, nil
+}
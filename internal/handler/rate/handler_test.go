@@ -0,0 +1,198 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domainRate "yenup/internal/domain/rate"
+	"yenup/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRateUsecase struct {
+	result *usecase.CheckRateResult
+	saved  int
+	err    error
+}
+
+func (m *mockRateUsecase) CheckRates(ctx context.Context, base, target domainRate.Currency, forceNotify bool) (*usecase.CheckRateResult, error) {
+	return m.result, m.err
+}
+
+func (m *mockRateUsecase) CheckMultipleRates(ctx context.Context, base domainRate.Currency, targets []domainRate.Currency, forceNotify bool) ([]*usecase.CheckRateResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*usecase.CheckRateResult{m.result}, nil
+}
+
+func (m *mockRateUsecase) BackfillHistory(ctx context.Context, base, target domainRate.Currency, from, to string) (int, error) {
+	return m.saved, m.err
+}
+
+type mockCurrencyUsecase struct {
+	currencies  []domainRate.Currency
+	listErr     error
+	validateErr error
+}
+
+func (m *mockCurrencyUsecase) ListCurrencies(ctx context.Context) ([]domainRate.Currency, error) {
+	return m.currencies, m.listErr
+}
+
+func (m *mockCurrencyUsecase) ValidateCurrencies(ctx context.Context, currencies ...domainRate.Currency) error {
+	return m.validateErr
+}
+
+func newTestRouter(rates *mockRateUsecase, currencies *mockCurrencyUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewRateHandler(rates, currencies)
+	r := gin.New()
+	r.GET("/check-rate", h.CheckRate)
+	r.GET("/currencies", h.ListCurrencies)
+	r.POST("/backfill-history", h.BackfillHistory)
+	return r
+}
+
+func TestCheckRate(t *testing.T) {
+	result := &usecase.CheckRateResult{
+		Base:          "CAD",
+		Target:        "JPY",
+		TodayRate:     domainRate.MustParseDecimal("110.22"),
+		YesterdayRate: domainRate.MustParseDecimal("112.50"),
+		IsNotified:    true,
+		Provider:      "frankfurter",
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		usecaseErr    error
+		validateErr   error
+		wantStatus    int
+		wantChange    string
+		wantConverted string
+	}{
+		{name: "success", query: "base=CAD&target=JPY", wantStatus: http.StatusOK, wantChange: "down (JPY stronger)"},
+		{name: "success: amount is converted", query: "base=CAD&target=JPY&amount=1000", wantStatus: http.StatusOK, wantChange: "down (JPY stronger)", wantConverted: "110220"},
+		{name: "error: missing target", query: "base=CAD", wantStatus: http.StatusBadRequest},
+		{name: "error: invalid amount", query: "base=CAD&target=JPY&amount=-5", wantStatus: http.StatusBadRequest},
+		{name: "error: amount too large", query: "base=CAD&target=JPY&amount=100000000000000000", wantStatus: http.StatusBadRequest},
+		{name: "error: invalid notification flag", query: "base=CAD&target=JPY&notification=maybe", wantStatus: http.StatusBadRequest},
+		{name: "error: unknown ISO code", query: "base=CAD&target=JYP", wantStatus: http.StatusBadRequest},
+		{name: "error: currency not offered", query: "base=CAD&target=JPY", validateErr: fmt.Errorf("%w: JPY", domainRate.ErrUnsupportedCurrency), wantStatus: http.StatusBadRequest},
+		{name: "error: rate not found", query: "base=CAD&target=JPY", usecaseErr: fmt.Errorf("wrapped: %w", domainRate.ErrRateNotFound), wantStatus: http.StatusNotFound},
+		{name: "error: quota exceeded", query: "base=CAD&target=JPY", usecaseErr: domainRate.ErrQuotaExceeded, wantStatus: http.StatusTooManyRequests},
+		{name: "error: malformed response", query: "base=CAD&target=JPY", usecaseErr: domainRate.ErrMalformedResponse, wantStatus: http.StatusBadGateway},
+		{name: "error: provider unavailable", query: "base=CAD&target=JPY,USD", usecaseErr: domainRate.ErrProviderUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "error: anything else", query: "base=CAD&target=JPY", usecaseErr: errors.New("storage down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(
+				&mockRateUsecase{result: result, err: tt.usecaseErr},
+				&mockCurrencyUsecase{validateErr: tt.validateErr},
+			)
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/check-rate?"+tt.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var body struct {
+				Status string   `json:"status"`
+				Data   RateData `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "success", body.Status)
+				assert.Equal(t, tt.wantChange, body.Data.Change)
+				assert.Equal(t, "frankfurter", body.Data.Provider)
+				if tt.wantConverted != "" && assert.NotNil(t, body.Data.Converted) {
+					assert.Equal(t, tt.wantConverted, body.Data.Converted.String())
+				}
+			} else {
+				assert.Equal(t, "error", body.Status)
+			}
+		})
+	}
+}
+
+func TestListCurrencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		currencies []domainRate.Currency
+		listErr    error
+		wantStatus int
+		wantCodes  []string
+	}{
+		{name: "success", currencies: []domainRate.Currency{"CAD", "JPY"}, wantStatus: http.StatusOK, wantCodes: []string{"CAD", "JPY"}},
+		{name: "error: provider cannot list", listErr: usecase.ErrCurrencyListUnsupported, wantStatus: http.StatusNotImplemented},
+		{name: "error: provider unavailable", listErr: fmt.Errorf("failed to list currencies: %w", domainRate.ErrProviderUnavailable), wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&mockRateUsecase{}, &mockCurrencyUsecase{currencies: tt.currencies, listErr: tt.listErr})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/currencies", nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var body struct {
+				Data []CurrencyData `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			var codes []string
+			for _, c := range body.Data {
+				codes = append(codes, c.Code)
+				assert.NotEmpty(t, c.Name)
+			}
+			assert.Equal(t, tt.wantCodes, codes)
+		})
+	}
+}
+
+func TestBackfillHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		usecaseErr error
+		wantStatus int
+		wantSaved  int
+	}{
+		{name: "success", query: "base=CAD&target=JPY&from=2026-01-01&to=2026-03-31", wantStatus: http.StatusOK, wantSaved: 61},
+		{name: "error: missing from", query: "base=CAD&target=JPY&to=2026-03-31", wantStatus: http.StatusBadRequest},
+		{name: "error: unknown ISO code", query: "base=CAD&target=JYP&from=2026-01-01", wantStatus: http.StatusBadRequest},
+		{name: "error: from after to", query: "base=CAD&target=JPY&from=2026-03-31&to=2026-01-01", wantStatus: http.StatusBadRequest},
+		{name: "error: range too long", query: "base=CAD&target=JPY&from=2024-01-01&to=2026-01-01", wantStatus: http.StatusBadRequest},
+		{name: "error: more rates than the history limit keeps", query: "base=CAD&target=JPY&from=2026-01-01&to=2026-03-31", usecaseErr: usecase.ErrBackfillExceedsHistoryLimit, wantStatus: http.StatusBadRequest},
+		{name: "error: fetcher cannot fetch a range", query: "base=CAD&target=JPY&from=2026-01-01&to=2026-03-31", usecaseErr: usecase.ErrBackfillUnsupported, wantStatus: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(&mockRateUsecase{saved: 61, err: tt.usecaseErr}, &mockCurrencyUsecase{})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/backfill-history?"+tt.query, nil)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var body struct {
+				Data BackfillData `json:"data"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantSaved, body.Data.Saved)
+		})
+	}
+}
@@ -6,5 +6,8 @@ import (
 
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/check-rate", h.RateHandler.CheckRate)
+	r.GET("/currencies", h.RateHandler.ListCurrencies)
+	r.POST("/backfill-history", h.RateHandler.BackfillHistory)
 	r.GET("/weekly-report", h.ReportHandler.GenerateReport)
+	r.GET("/health", h.HealthHandler.Health)
 }
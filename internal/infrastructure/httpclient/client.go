@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ErrResponseTooLarge is returned while reading a response body longer than Options.MaxResponseBytes
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// Options configures the outbound HTTP client shared by every rate fetcher and notifier.
+type Options struct {
+	ProxyURL            string        // overrides HTTP_PROXY/HTTPS_PROXY/NO_PROXY when set
+	CABundle            string        // PEM file trusted in addition to the system roots
+	UserAgent           string        // set on requests that do not carry one
+	TLSMinVersion       string        // "1.2" or "1.3"
+	MaxIdleConns        int           // idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // idle connections kept per host
+	MaxConnsPerHost     int           // concurrent connections per host; 0 means unlimited
+	IdleConnTimeout     time.Duration // how long an idle connection is kept
+	MaxResponseBytes    int64         // response bodies beyond this fail with ErrResponseTooLarge; 0 means unlimited
+	Retry               RetryPolicy
+}
+
+// New builds an *http.Client from opts. Requests pass through, outermost first:
+// retries, the User-Agent and response size limit, then the pooled TLS transport.
+func New(opts Options) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConns = opts.MaxIdleConns
+	base.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	base.MaxConnsPerHost = opts.MaxConnsPerHost
+	base.IdleConnTimeout = opts.IdleConnTimeout
+
+	if opts.ProxyURL != "" {
+		proxy, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		base.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	switch opts.TLSMinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS minimum version: %q", opts.TLSMinVersion)
+	}
+	if opts.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	base.TLSClientConfig = tlsConfig
+
+	limited := &limitTransport{
+		Base:      base,
+		UserAgent: opts.UserAgent,
+		MaxBytes:  opts.MaxResponseBytes,
+	}
+	return &http.Client{
+		Transport: NewRetryTransport(limited, opts.Retry),
+	}, nil
+}
+
+// limitTransport sets the User-Agent and caps how much of a response body can be read
+type limitTransport struct {
+	Base      http.RoundTripper
+	UserAgent string
+	MaxBytes  int64
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.UserAgent)
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || t.MaxBytes <= 0 {
+		return resp, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.MaxBytes}
+	return resp, nil
+}
+
+// limitedBody fails once more than the allowed bytes were read, instead of silently truncating.
+// The error surfaces from io.ReadAll, so a huge body is never buffered whole.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// read one byte past the limit to tell an exact fit from an overflow
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
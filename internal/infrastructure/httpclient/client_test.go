@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int64
+		wantErr  error
+	}{
+		{name: "success: no limit", body: strings.Repeat("x", 64)},
+		{name: "success: body fits the limit exactly", body: strings.Repeat("x", 16), maxBytes: 16},
+		{name: "error: body over the limit", body: strings.Repeat("x", 17), maxBytes: 16, wantErr: ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "yenup-test", r.Header.Get("User-Agent"))
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client, err := New(Options{UserAgent: "yenup-test", MaxResponseBytes: tt.maxBytes, Retry: RetryPolicy{MaxAttempts: 1}})
+			assert.NoError(t, err)
+
+			resp, err := client.Get(srv.URL)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	_, err := New(Options{TLSMinVersion: "1.0"})
+	assert.Error(t, err)
+
+	_, err = New(Options{CABundle: "testdata/missing.pem"})
+	assert.Error(t, err)
+}
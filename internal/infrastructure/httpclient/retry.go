@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how transient failures of outbound HTTP calls are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first one; 1 disables retries
+	BaseDelay   time.Duration // delay before the second attempt, doubled for each further attempt
+	MaxDelay    time.Duration // upper bound for a single delay, including Retry-After
+	Jitter      float64       // random spread applied to each delay, between 0 and 1, e.g. 0.2 for ±20%
+}
+
+// backoff returns the delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		// a non-positive delay means the shift overflowed
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		// a spread above 100% could make the delay negative
+		spread := float64(delay) * min(p.Jitter, 1)
+		delay += time.Duration(spread * (2*rand.Float64() - 1))
+	}
+	return delay
+}
+
+type retryHookKey struct{}
+
+// WithRetryHook returns a context whose requests call hook before every retry, for example
+// to meter each attempt against a provider quota. An error from hook stops retrying, and
+// the outcome of the last attempt is returned.
+func WithRetryHook(ctx context.Context, hook func() error) context.Context {
+	return context.WithValue(ctx, retryHookKey{}, hook)
+}
+
+// RetryTransport is an http.RoundTripper that retries network errors, 429 and 5xx
+// responses according to Policy. A Retry-After header overrides the computed backoff.
+// Network errors are retried only when the request is idempotent or was never sent,
+// so a POST or PUT that may have reached the server is not repeated.
+type RetryTransport struct {
+	Base   http.RoundTripper
+	Policy RetryPolicy
+}
+
+// NewRetryTransport wraps base (http.DefaultTransport when nil) with policy
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		Base:   base,
+		Policy: policy,
+	}
+}
+
+// RoundTrip sends req, retrying transient failures until attempts run out or the request context ends.
+// Each attempt sends its own clone of req, since a RoundTripper must not modify the request.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := max(t.Policy.MaxAttempts, 1)
+	target := req.URL.Host + req.URL.Path // never log the query, it may carry API keys
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(req.Context())
+			if req.Body != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.Base.RoundTrip(attemptReq)
+		if !shouldRetry(req, resp, err) || req.Context().Err() != nil {
+			if attempt > 1 {
+				log.Printf("%s %s: finished after %d attempts", req.Method, target, attempt)
+			}
+			return resp, err
+		}
+		if attempt >= attempts || (req.Body != nil && req.GetBody == nil) {
+			log.Printf("%s %s: giving up after %d attempts: %s", req.Method, target, attempt, describe(resp, err))
+			return resp, err
+		}
+
+		if hook, ok := req.Context().Value(retryHookKey{}).(func() error); ok {
+			if hookErr := hook(); hookErr != nil {
+				log.Printf("%s %s: not retrying after %d attempts: %v", req.Method, target, attempt, hookErr)
+				return resp, err
+			}
+		}
+
+		delay := t.Policy.backoff(attempt)
+		if after, ok := retryAfter(resp); ok {
+			delay = after
+			if t.Policy.MaxDelay > 0 && delay > t.Policy.MaxDelay {
+				delay = t.Policy.MaxDelay
+			}
+		}
+		log.Printf("%s %s: attempt %d/%d failed: %s; retrying in %s", req.Method, target, attempt, attempts, describe(resp, err), delay)
+
+		if resp != nil {
+			// drain so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether the outcome of one attempt is transient and safe to repeat
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(req) || notSent(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// isIdempotent reports whether repeating req cannot change anything on the server.
+// PUT is left out: a conditional upload that succeeded would fail its precondition when repeated.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// notSent reports whether err happened while connecting, before any of the request was written
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func describe(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
@@ -0,0 +1,163 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "success: first attempt", statuses: []int{200}, wantStatus: 200, wantCalls: 1},
+		{name: "success: after a 503", statuses: []int{503, 200}, wantStatus: 200, wantCalls: 2},
+		{name: "success: after a 429 with Retry-After", statuses: []int{429, 200}, retryAfter: "0", wantStatus: 200, wantCalls: 2},
+		{name: "error: 404 is not retried", statuses: []int{404, 200}, wantStatus: 404, wantCalls: 1},
+		{name: "error: attempts run out", statuses: []int{500, 502, 503, 200}, wantStatus: 503, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var bodies []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: NewRetryTransport(nil, RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    10 * time.Millisecond,
+				Jitter:      0.5,
+			})}
+			resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"text":"hi"}`))
+
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls)
+			for _, b := range bodies {
+				assert.Equal(t, `{"text":"hi"}`, b, "body must be resent on every attempt")
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Hook(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    int
+		wantStatus int
+		wantCalls  int
+		wantHooks  int
+	}{
+		{name: "success: every retry is allowed", allowed: 2, wantStatus: 200, wantCalls: 3, wantHooks: 2},
+		{name: "error: a refused retry returns the last attempt", allowed: 1, wantStatus: 503, wantCalls: 2, wantHooks: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			hooks := 0
+			ctx := WithRetryHook(context.Background(), func() error {
+				hooks++
+				if hooks > tt.allowed {
+					return errors.New("quota used up")
+				}
+				return nil
+			})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			client := &http.Client{Transport: NewRetryTransport(nil, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})}
+			resp, err := client.Do(req)
+
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantHooks, hooks)
+		})
+	}
+}
+
+// failingTransport fails every request with err and counts the attempts
+type failingTransport struct {
+	err    error
+	bodies []io.ReadCloser
+}
+
+func (f *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.bodies = append(f.bodies, req.Body)
+	return nil, f.err
+}
+
+func TestRetryTransport_NetworkErrors(t *testing.T) {
+	reset := errors.New("connection reset by peer")
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name      string
+		method    string
+		err       error
+		wantCalls int
+	}{
+		{name: "GET is retried", method: http.MethodGet, err: reset, wantCalls: 3},
+		{name: "POST is not retried once it may have been sent", method: http.MethodPost, err: reset, wantCalls: 1},
+		{name: "PUT is not retried once it may have been sent", method: http.MethodPut, err: reset, wantCalls: 1},
+		{name: "POST is retried when the connection failed", method: http.MethodPost, err: refused, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &failingTransport{err: tt.err}
+			transport := NewRetryTransport(base, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+			body := strings.NewReader(`{"text":"hi"}`)
+			req, _ := http.NewRequest(tt.method, "http://example.com/hook", body)
+			original := req.Body
+
+			_, err := transport.RoundTrip(req)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Len(t, base.bodies, tt.wantCalls)
+			// the caller's request is left as it was
+			assert.Equal(t, original, req.Body)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 5}
+
+	for range 100 {
+		delay := policy.backoff(1)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+}
@@ -2,25 +2,44 @@ package notifier
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
 type SlackNotifier struct {
 	WebhookURL string
+	Client     *http.Client
 }
 
-func NewSlackNotifier(webhookURL string) *SlackNotifier {
+func NewSlackNotifier(webhookURL string, client *http.Client) *SlackNotifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
 	return &SlackNotifier{
 		WebhookURL: webhookURL,
+		Client:     client,
 	}
 }
 
 func (s *SlackNotifier) Notify(message string) error {
-	// Create JSON payload
-	payload := fmt.Sprintf(`{"text":"%s"}`, message)
+	// Create JSON payload; newlines and quotes in message must be escaped
+	payload, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
 
 	// Send POST request to Slack webhook URL
-	_, err := http.Post(s.WebhookURL, "application/json", bytes.NewBuffer([]byte(payload)))
-	return err
+	resp, err := s.Client.Post(s.WebhookURL, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook error: %s", resp.Status)
+	}
+	return nil
 }
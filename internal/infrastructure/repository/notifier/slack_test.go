@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	message := "This week report.\nCAD/JPY Average: 112.90, Max: 113.22, Min: 112.58\nsaid \"hi\""
+
+	var got struct {
+		Text string `json:"text"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	err := NewSlackNotifier(srv.URL, srv.Client()).Notify(message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, message, got.Text)
+}
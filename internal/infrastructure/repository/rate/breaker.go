@@ -0,0 +1,155 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// BreakerFetcher is a circuit breaker around a single provider.
+// After Threshold consecutive provider failures it opens and fails fast with
+// domain.ErrCircuitOpen. Once Cooldown has passed, one trial call is let through
+// (half-open): success closes the breaker, failure opens it again.
+type BreakerFetcher struct {
+	Name      string
+	Fetcher   domain.RateFetcher
+	Threshold int
+	Cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool // a half-open trial call is in flight
+}
+
+// NewBreakerFetcher creates a new BreakerFetcher in the closed state
+func NewBreakerFetcher(name string, fetcher domain.RateFetcher, threshold int, cooldown time.Duration) *BreakerFetcher {
+	return &BreakerFetcher{
+		Name:      name,
+		Fetcher:   fetcher,
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		now:       time.Now,
+		state:     domain.BreakerClosed,
+	}
+}
+
+// FetchRate calls the provider unless the breaker is open
+func (b *BreakerFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	if err := b.allow(); err != nil {
+		return domain.Rate{}, err
+	}
+	r, err := b.Fetcher.FetchRate(ctx, date, base, target)
+	b.record(ctx, err)
+	return r, err
+}
+
+// FetchRates calls the provider unless the breaker is open.
+// Providers without batch support are queried one target at a time.
+func (b *BreakerFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+
+	var rates []domain.Rate
+	var err error
+	if batch, ok := b.Fetcher.(domain.BatchFetcher); ok {
+		rates, err = batch.FetchRates(ctx, date, base, targets)
+	} else {
+		for _, target := range targets {
+			var r domain.Rate
+			if r, err = b.Fetcher.FetchRate(ctx, date, base, target); err != nil {
+				rates = nil
+				break
+			}
+			rates = append(rates, r)
+		}
+	}
+	b.record(ctx, err)
+	return rates, err
+}
+
+// FetchRange calls the provider unless the breaker is open.
+// Providers without a series endpoint are queried one day at a time.
+func (b *BreakerFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	rates, err := fetchRange(ctx, b.Fetcher, from, to, base, target)
+	b.record(ctx, err)
+	return rates, err
+}
+
+// Status reports the current breaker state
+func (b *BreakerFetcher) Status() domain.ProviderStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return domain.ProviderStatus{
+		Name:     b.Name,
+		State:    b.currentState(),
+		Failures: b.failures,
+	}
+}
+
+// allow decides whether a call may go through and claims the half-open trial slot
+func (b *BreakerFetcher) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case domain.BreakerOpen:
+		return fmt.Errorf("%w: %s", domain.ErrCircuitOpen, b.Name)
+	case domain.BreakerHalfOpen:
+		if b.trial {
+			return fmt.Errorf("%w: %s is being probed", domain.ErrCircuitOpen, b.Name)
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *BreakerFetcher) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasTrial := b.trial
+	b.trial = false
+
+	switch {
+	case err == nil || (!domain.IsRetryable(err) && ctx.Err() == nil):
+		// the provider answered; caller mistakes such as unknown currencies do not count
+		if b.state != domain.BreakerClosed {
+			log.Printf("rate provider %s recovered, circuit closed", b.Name)
+		}
+		b.state = domain.BreakerClosed
+		b.failures = 0
+	case ctx.Err() != nil, errors.Is(err, domain.ErrQuotaUsedUp):
+		// the caller gave up or the local meter refused the call, which says nothing about the provider
+	default:
+		b.failures++
+		if wasTrial || b.failures >= b.Threshold {
+			if b.state != domain.BreakerOpen {
+				log.Printf("rate provider %s tripped after %d failures, circuit open for %s: %v", b.Name, b.failures, b.Cooldown, err)
+			}
+			b.state = domain.BreakerOpen
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// currentState resolves an open breaker whose cool-down has passed to half-open.
+// Callers must hold b.mu.
+func (b *BreakerFetcher) currentState() string {
+	if b.state == domain.BreakerOpen && b.now().Sub(b.openedAt) >= b.Cooldown {
+		b.state = domain.BreakerHalfOpen
+	}
+	return b.state
+}
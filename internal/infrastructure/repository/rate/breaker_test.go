@@ -0,0 +1,76 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerFetcher(t *testing.T) {
+	ctx := context.Background()
+	inner := &stubFetcher{err: domain.ErrProviderUnavailable}
+	b := NewBreakerFetcher("exchangerates", inner, 2, time.Minute)
+	clock := time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return clock }
+
+	// two provider failures trip the breaker
+	for i := 0; i < 2; i++ {
+		_, err := b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+		assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+	}
+	assert.Equal(t, domain.BreakerOpen, b.Status().State)
+
+	// while open, calls fail fast without reaching the provider
+	_, err := b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+	assert.ErrorIs(t, err, domain.ErrCircuitOpen)
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+	assert.Equal(t, 2, inner.calls)
+
+	// after the cool-down a failed trial call opens it again
+	clock = clock.Add(time.Minute)
+	assert.Equal(t, domain.BreakerHalfOpen, b.Status().State)
+	_, err = b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+	assert.NotErrorIs(t, err, domain.ErrCircuitOpen)
+	assert.Equal(t, 3, inner.calls)
+	assert.Equal(t, domain.BreakerOpen, b.Status().State)
+
+	// a successful trial call closes it
+	clock = clock.Add(time.Minute)
+	inner.err = nil
+	_, err = b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ProviderStatus{Name: "exchangerates", State: domain.BreakerClosed}, b.Status())
+}
+
+func TestBreakerFetcher_IgnoresCallerErrors(t *testing.T) {
+	inner := &stubFetcher{err: domain.ErrUnsupportedCurrency}
+	b := NewBreakerFetcher("frankfurter", inner, 1, time.Minute)
+
+	_, err := b.FetchRate(context.Background(), "2026-03-19", "CAD", "JYP")
+
+	assert.ErrorIs(t, err, domain.ErrUnsupportedCurrency)
+	assert.Equal(t, domain.BreakerClosed, b.Status().State)
+}
+
+func TestBreakerFetcher_IgnoresUsedUpQuota(t *testing.T) {
+	ctx := context.Background()
+	inner := &stubFetcher{rate: domain.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22")}}
+	q := NewQuotaFetcher("exchangerates", inner, nil, NewMemoryUsageStore(), 1, 0, nil)
+	q.now = func() time.Time { return time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC) }
+	b := NewBreakerFetcher("exchangerates", q, 2, time.Minute)
+
+	_, err := b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = b.FetchRate(ctx, "2026-03-19", "CAD", "JPY")
+		assert.ErrorIs(t, err, domain.ErrQuotaUsedUp)
+	}
+
+	// the provider never failed, so the breaker stays closed and /health stays green
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, domain.BreakerClosed, b.Status().State)
+}
@@ -0,0 +1,206 @@
+package rate
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// CachingFetcher caches rates by (date, base, target) in front of another RateFetcher.
+// Rates published for a past date never change and are cached forever; today's and
+// "latest" rates, and past dates answered with another day's rate, are cached for TTL only.
+type CachingFetcher struct {
+	Fetcher domain.RateFetcher
+	Cache   domain.Cache
+	TTL     time.Duration
+	now     func() time.Time
+}
+
+// NewCachingFetcher creates a new CachingFetcher
+func NewCachingFetcher(fetcher domain.RateFetcher, cache domain.Cache, ttl time.Duration) *CachingFetcher {
+	return &CachingFetcher{
+		Fetcher: fetcher,
+		Cache:   cache,
+		TTL:     ttl,
+		now:     time.Now,
+	}
+}
+
+// FetchRate returns the cached rate when available, otherwise fetches and caches it
+func (f *CachingFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	if r, ok := f.get(ctx, date, base, target); ok {
+		return r, nil
+	}
+
+	r, err := f.Fetcher.FetchRate(ctx, date, base, target)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	f.set(ctx, date, base, target, r)
+	return r, nil
+}
+
+// FetchRates serves cached targets from the cache and fetches only the missing ones,
+// in a single call when the wrapped fetcher supports batches
+func (f *CachingFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	rates := make([]domain.Rate, len(targets))
+	var missing []domain.Currency
+	var missingIdx []int
+	for i, target := range targets {
+		if r, ok := f.get(ctx, date, base, target); ok {
+			rates[i] = r
+			continue
+		}
+		missing = append(missing, target)
+		missingIdx = append(missingIdx, i)
+	}
+	if len(missing) == 0 {
+		return rates, nil
+	}
+
+	var fetched []domain.Rate
+	if batch, ok := f.Fetcher.(domain.BatchFetcher); ok {
+		var err error
+		if fetched, err = batch.FetchRates(ctx, date, base, missing); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, target := range missing {
+			r, err := f.Fetcher.FetchRate(ctx, date, base, target)
+			if err != nil {
+				return nil, err
+			}
+			fetched = append(fetched, r)
+		}
+	}
+
+	for j, r := range fetched {
+		rates[missingIdx[j]] = r
+		f.set(ctx, date, base, missing[j], r)
+	}
+	return rates, nil
+}
+
+// FetchRange always asks the wrapped fetcher, since a series cannot be told complete
+// from cached days, and caches each rate under its effective date
+func (f *CachingFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	rates, err := fetchRange(ctx, f.Fetcher, from, to, base, target)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rates {
+		r.RequestedDate = r.EffectiveDate
+		f.set(ctx, r.EffectiveDate, base, target, r)
+	}
+	return rates, nil
+}
+
+// get looks up the cache; cache failures are logged and treated as a miss
+func (f *CachingFetcher) get(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, bool) {
+	entry, ok, err := f.Cache.Get(ctx, cacheKey(date, base, target))
+	if err != nil {
+		log.Printf("rate cache read failed: %v", err)
+		return domain.Rate{}, false
+	}
+	if !ok || entry.Expired(f.now()) {
+		return domain.Rate{}, false
+	}
+	return entry.Rate, true
+}
+
+// set stores r; cache failures are logged because the fetched rate is still valid.
+// Only a rate published for date itself and agreed on by the providers is final; a
+// stand-in for a closed day or a disputed value may still be corrected, so it expires.
+func (f *CachingFetcher) set(ctx context.Context, date string, base, target domain.Currency, r domain.Rate) {
+	entry := domain.CacheEntry{Rate: r}
+	final := f.isHistorical(date) && r.EffectiveDate == date && !r.Divergent && !r.Unconfirmed
+	if !final {
+		entry.ExpiresAt = f.now().Add(f.TTL)
+	}
+	if err := f.Cache.Set(ctx, cacheKey(date, base, target), entry); err != nil {
+		log.Printf("rate cache write failed: %v", err)
+	}
+}
+
+// isHistorical reports whether date is a fixed day before today
+func (f *CachingFetcher) isHistorical(date string) bool {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		// "latest" and anything else that is not a plain date can change
+		return false
+	}
+	return date < f.now().Format("2006-01-02")
+}
+
+func cacheKey(date string, base, target domain.Currency) string {
+	return date + "|" + string(base) + "|" + string(target)
+}
+
+// CachingCurrencyLister keeps a provider's currency list for TTL, since it rarely changes
+type CachingCurrencyLister struct {
+	Lister domain.CurrencyLister
+	TTL    time.Duration
+
+	mu         sync.Mutex
+	currencies []domain.Currency
+	expiresAt  time.Time
+	now        func() time.Time
+}
+
+// NewCachingCurrencyLister creates a new CachingCurrencyLister
+func NewCachingCurrencyLister(lister domain.CurrencyLister, ttl time.Duration) *CachingCurrencyLister {
+	return &CachingCurrencyLister{
+		Lister: lister,
+		TTL:    ttl,
+		now:    time.Now,
+	}
+}
+
+// ListCurrencies returns the cached list, refreshing it once it expired.
+// Failed refreshes are not cached.
+func (l *CachingCurrencyLister) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.currencies != nil && l.now().Before(l.expiresAt) {
+		return l.currencies, nil
+	}
+	currencies, err := l.Lister.ListCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.currencies = currencies
+	l.expiresAt = l.now().Add(l.TTL)
+	return currencies, nil
+}
+
+// MemoryCache is an in-process domain.Cache
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]domain.CacheEntry
+}
+
+// NewMemoryCache creates a new empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]domain.CacheEntry),
+	}
+}
+
+// Get returns the entry stored under key
+func (m *MemoryCache) Get(ctx context.Context, key string) (domain.CacheEntry, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.entries[key]
+	return entry, ok, nil
+}
+
+// Set stores entry under key
+func (m *MemoryCache) Set(ctx context.Context, key string, entry domain.CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,75 @@
+package rate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingFetcher(t *testing.T) {
+	now := time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		date      string
+		effective string
+		divergent bool
+		elapsed   time.Duration
+		wantCalls int
+	}{
+		{name: "past date is cached forever", date: "2026-03-18", effective: "2026-03-18", elapsed: 365 * 24 * time.Hour, wantCalls: 1},
+		{name: "past closed day is refetched after TTL", date: "2026-03-15", effective: "2026-03-13", elapsed: 20 * time.Minute, wantCalls: 2},
+		{name: "past divergent rate is refetched after TTL", date: "2026-03-18", effective: "2026-03-18", divergent: true, elapsed: 20 * time.Minute, wantCalls: 2},
+		{name: "today is cached within TTL", date: "2026-03-19", effective: "2026-03-18", elapsed: 5 * time.Minute, wantCalls: 1},
+		{name: "today is refetched after TTL", date: "2026-03-19", effective: "2026-03-18", elapsed: 20 * time.Minute, wantCalls: 2},
+		{name: "latest is refetched after TTL", date: "latest", effective: "2026-03-18", elapsed: 20 * time.Minute, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &stubFetcher{rate: domain.Rate{EffectiveDate: tt.effective, Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22"), Divergent: tt.divergent}}
+			f := NewCachingFetcher(inner, NewMemoryCache(), 15*time.Minute)
+			clock := now
+			f.now = func() time.Time { return clock }
+
+			_, err := f.FetchRate(context.Background(), tt.date, "CAD", "JPY")
+			assert.NoError(t, err)
+
+			clock = clock.Add(tt.elapsed)
+			got, err := f.FetchRate(context.Background(), tt.date, "CAD", "JPY")
+
+			assert.NoError(t, err)
+			assert.Equal(t, domain.MustParseDecimal("110.22"), got.Value)
+			assert.Equal(t, tt.wantCalls, inner.calls)
+		})
+	}
+}
+
+type stubLister struct {
+	currencies []domain.Currency
+	calls      int
+}
+
+func (s *stubLister) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	s.calls++
+	return s.currencies, nil
+}
+
+func TestCachingCurrencyLister(t *testing.T) {
+	inner := &stubLister{currencies: []domain.Currency{"CAD", "JPY"}}
+	l := NewCachingCurrencyLister(inner, 24*time.Hour)
+	clock := time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return clock }
+
+	for _, elapsed := range []time.Duration{0, 23 * time.Hour, 2 * time.Hour} {
+		clock = clock.Add(elapsed)
+		got, err := l.ListCurrencies(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Currency{"CAD", "JPY"}, got)
+	}
+	assert.Equal(t, 2, inner.calls)
+}
@@ -0,0 +1,144 @@
+package rate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// ProviderCommand is the provider name recorded in rates produced by an external command
+const ProviderCommand = "command"
+
+// Ways CommandFetcher passes the query to the command
+const (
+	CommandInputArgs = "args" // date base target as arguments
+	CommandInputJSON = "json" // {"date","base","target"} on stdin
+)
+
+// commandWaitDelay bounds how long the command's output pipes are drained after it was killed
+const commandWaitDelay = time.Second
+
+// CommandRequest is the JSON written to the command's stdin in CommandInputJSON mode
+type CommandRequest struct {
+	Date   string          `json:"date"`
+	Base   domain.Currency `json:"base"`
+	Target domain.Currency `json:"target"`
+}
+
+// CommandResponse is the JSON the command prints on stdout.
+// Only the date and value are read; the pair and provider come from the request.
+type CommandResponse struct {
+	Date  string         `json:"date"`
+	Value domain.Decimal `json:"value"`
+}
+
+// CommandFetcher runs an executable to fetch a rate, so internal scripts can act as providers.
+// The command prints a JSON CommandResponse on stdout and exits 0; any other exit code is a failure.
+type CommandFetcher struct {
+	Path      string
+	Input     string        // CommandInputArgs or CommandInputJSON
+	Timeout   time.Duration // per run; zero means no extra deadline beyond ctx
+	MaxOutput int64         // stdout bytes accepted; zero means unlimited
+}
+
+// NewCommandFetcher creates a new CommandFetcher
+func NewCommandFetcher(path, input string, timeout time.Duration, maxOutput int64) *CommandFetcher {
+	return &CommandFetcher{
+		Path:      path,
+		Input:     input,
+		Timeout:   timeout,
+		MaxOutput: maxOutput,
+	}
+}
+
+// FetchRate runs the command for date, base and target and parses its output.
+// A missing date in the output defaults to the requested one.
+func (f *CommandFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch f.Input {
+	case CommandInputJSON:
+		input, err := json.Marshal(CommandRequest{Date: date, Base: base, Target: target})
+		if err != nil {
+			return domain.Rate{}, fmt.Errorf("failed to encode command input: %w", err)
+		}
+		cmd = exec.CommandContext(ctx, f.Path)
+		cmd.Stdin = bytes.NewReader(input)
+	case CommandInputArgs, "":
+		cmd = exec.CommandContext(ctx, f.Path, date, string(base), string(target))
+	default:
+		return domain.Rate{}, fmt.Errorf("unknown command input mode: %q", f.Input)
+	}
+	cmd.WaitDelay = commandWaitDelay
+
+	stdout := &cappedBuffer{max: f.MaxOutput}
+	stderr := &cappedBuffer{max: 4 << 10}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return domain.Rate{}, fmt.Errorf("%w: command %s: %w", domain.ErrProviderUnavailable, f.Path, ctx.Err())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return domain.Rate{}, fmt.Errorf("%w: command %s exited with code %d: %s",
+				domain.ErrProviderUnavailable, f.Path, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		}
+		return domain.Rate{}, fmt.Errorf("%w: failed to run command %s: %w", domain.ErrProviderUnavailable, f.Path, err)
+	}
+	if stdout.overflow {
+		return domain.Rate{}, fmt.Errorf("%w: command output exceeds %d bytes", domain.ErrMalformedResponse, f.MaxOutput)
+	}
+
+	var resp CommandResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return domain.Rate{}, fmt.Errorf("%w: failed to parse command output: %w", domain.ErrMalformedResponse, err)
+	}
+	if resp.Value.Sign() <= 0 {
+		return domain.Rate{}, fmt.Errorf("%w: command returned no positive value", domain.ErrMalformedResponse)
+	}
+	if resp.Date == "" {
+		resp.Date = date
+	}
+	return domain.Rate{
+		EffectiveDate: resp.Date,
+		RequestedDate: date,
+		Base:          base,
+		Target:        target,
+		Value:         resp.Value,
+		Provider:      ProviderCommand,
+	}, nil
+}
+
+// cappedBuffer keeps the first max bytes written and discards the rest,
+// so a chatty command never blocks on a full pipe.
+// bytes.Buffer is not embedded because its ReadFrom would let io.Copy bypass the cap.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	max      int64
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 {
+		if room := b.max - int64(b.buf.Len()); int64(len(p)) > room {
+			b.overflow = true
+			b.buf.Write(p[:max(room, 0)])
+			return len(p), nil
+		}
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) Bytes() []byte  { return b.buf.Bytes() }
+func (b *cappedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,88 @@
+package rate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeScript writes an executable shell script and returns its path
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rate.sh")
+	assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755))
+	return path
+}
+
+func TestCommandFetcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		script    string
+		input     string
+		timeout   time.Duration
+		maxOutput int64
+		wantRate  domain.Rate
+		wantErr   error
+	}{
+		{
+			name:     "success: query passed as arguments",
+			script:   `echo "{\"date\":\"$1\",\"base\":\"$2\",\"target\":\"$3\",\"value\":110.22}"`,
+			input:    CommandInputArgs,
+			wantRate: domain.Rate{EffectiveDate: "2026-03-19", RequestedDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22"), Provider: ProviderCommand},
+		},
+		{
+			name:     "success: query passed as JSON on stdin",
+			script:   `grep -q '"target":"JPY"' && echo '{"date":"2026-03-18","value":"110.5"}'`,
+			input:    CommandInputJSON,
+			wantRate: domain.Rate{EffectiveDate: "2026-03-18", RequestedDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.5"), Provider: ProviderCommand},
+		},
+		{
+			name:    "error: non-zero exit code",
+			script:  `echo "upstream down" >&2; exit 3`,
+			wantErr: domain.ErrProviderUnavailable,
+		},
+		{
+			name:    "error: timeout",
+			script:  `exec sleep 5`,
+			timeout: 50 * time.Millisecond,
+			wantErr: domain.ErrProviderUnavailable,
+		},
+		{
+			name:      "error: output too large",
+			script:    `echo '{"value":110.22,"provider":"a-very-long-provider-name"}'`,
+			maxOutput: 16,
+			wantErr:   domain.ErrMalformedResponse,
+		},
+		{
+			name:     "success: fields other than date and value are ignored",
+			script:   `echo '{"base":"USD","target":"EUR","value":110.22,"provider":"treasury","divergent":true,"derived":true,"pivot":"EUR","spread":0.5}'`,
+			wantRate: domain.Rate{EffectiveDate: "2026-03-19", RequestedDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22"), Provider: ProviderCommand},
+		},
+		{
+			name:    "error: value missing",
+			script:  `echo '{"date":"2026-03-19"}'`,
+			wantErr: domain.ErrMalformedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewCommandFetcher(writeScript(t, tt.script), tt.input, tt.timeout, tt.maxOutput)
+
+			got, err := f.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, got)
+		})
+	}
+}
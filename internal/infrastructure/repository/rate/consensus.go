@@ -0,0 +1,196 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	domain "yenup/internal/domain/rate"
+)
+
+var half = domain.MustParseDecimal("0.5")
+
+// ConsensusFetcher queries every provider concurrently and returns the median value.
+// The maximum relative spread between providers is recorded in rate.Rate.Spread, and
+// the rate is marked Divergent when the spread exceeds Tolerance, or Unconfirmed when
+// only one provider answered.
+type ConsensusFetcher struct {
+	Providers []NamedFetcher
+	Tolerance float64 // maximum accepted relative spread, e.g. 0.01 for 1%
+}
+
+// NewConsensusFetcher creates a new ConsensusFetcher
+func NewConsensusFetcher(tolerance float64, providers ...NamedFetcher) *ConsensusFetcher {
+	return &ConsensusFetcher{
+		Providers: providers,
+		Tolerance: tolerance,
+	}
+}
+
+// FetchRate fetches base/target from every provider and combines the answers.
+// Providers that fail are left out; an error is returned only if none of them answered.
+func (f *ConsensusFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	if len(f.Providers) == 0 {
+		return domain.Rate{}, fmt.Errorf("%w: no providers configured", domain.ErrProviderUnavailable)
+	}
+
+	rates := make([]domain.Rate, len(f.Providers))
+	errs := make([]error, len(f.Providers))
+
+	var wg sync.WaitGroup
+	for i, p := range f.Providers {
+		wg.Add(1)
+		go func(i int, p NamedFetcher) {
+			defer wg.Done()
+			r, err := p.Fetcher.FetchRate(ctx, date, base, target)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Name, err)
+				return
+			}
+			r.Provider = p.Name
+			rates[i] = r
+		}(i, p)
+	}
+	wg.Wait()
+
+	var answered []domain.Rate
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("rate provider %s left out of consensus: %v", f.Providers[i].Name, err)
+			continue
+		}
+		answered = append(answered, rates[i])
+	}
+	if len(answered) == 0 {
+		return domain.Rate{}, errors.Join(errs...)
+	}
+
+	return f.combine(answered)
+}
+
+// FetchRange fetches the base/target series from every provider and combines the
+// answers for each effective date. Providers that fail are left out; an error is
+// returned only if none of them answered.
+func (f *ConsensusFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	if len(f.Providers) == 0 {
+		return nil, fmt.Errorf("%w: no providers configured", domain.ErrProviderUnavailable)
+	}
+
+	series := make([][]domain.Rate, len(f.Providers))
+	errs := make([]error, len(f.Providers))
+
+	var wg sync.WaitGroup
+	for i, p := range f.Providers {
+		wg.Add(1)
+		go func(i int, p NamedFetcher) {
+			defer wg.Done()
+			rates, err := fetchRange(ctx, p.Fetcher, from, to, base, target)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.Name, err)
+				return
+			}
+			for j := range rates {
+				rates[j].Provider = p.Name
+			}
+			series[i] = rates
+		}(i, p)
+	}
+	wg.Wait()
+
+	byDate := make(map[string][]domain.Rate)
+	answered := 0
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("rate provider %s left out of consensus: %v", f.Providers[i].Name, err)
+			continue
+		}
+		answered++
+		for _, r := range series[i] {
+			byDate[r.EffectiveDate] = append(byDate[r.EffectiveDate], r)
+		}
+	}
+	if answered == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	rates := make([]domain.Rate, 0, len(dates))
+	for _, date := range dates {
+		r, err := f.combine(byDate[date])
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+// combine builds the consensus rate from the providers that answered. Only answers for
+// the same effective date are comparable, so the date most providers answered with is
+// used (the latest on a tie) and the others are left out. A rate that fewer than two
+// providers agree on is marked Unconfirmed.
+func (f *ConsensusFetcher) combine(answered []domain.Rate) (domain.Rate, error) {
+	byDate := make(map[string][]domain.Rate)
+	for _, r := range answered {
+		byDate[r.EffectiveDate] = append(byDate[r.EffectiveDate], r)
+	}
+	date := ""
+	for d, rates := range byDate {
+		if len(rates) > len(byDate[date]) || (len(rates) == len(byDate[date]) && d > date) {
+			date = d
+		}
+	}
+	for _, r := range answered {
+		if r.EffectiveDate != date {
+			log.Printf("rate provider %s left out of consensus: answered for %s instead of %s", r.Provider, r.EffectiveDate, date)
+		}
+	}
+	answered = byDate[date]
+
+	sort.Slice(answered, func(i, j int) bool {
+		return answered[i].Value.LessThan(answered[j].Value)
+	})
+
+	mid := len(answered) / 2
+	median := answered[mid].Value
+	if len(answered)%2 == 0 {
+		// halving is exact: it adds at most one decimal
+		sum, err := answered[mid-1].Value.Add(answered[mid].Value)
+		if err == nil {
+			median, err = sum.Mul(half)
+		}
+		if err != nil {
+			return domain.Rate{}, fmt.Errorf("failed to compute the median: %w", err)
+		}
+	}
+
+	// the spread is a statistic, so float64 is precise enough
+	spread := 0.0
+	if !median.IsZero() {
+		spread = (answered[len(answered)-1].Value.Float64() - answered[0].Value.Float64()) / median.Float64()
+	}
+
+	names := make([]string, len(answered))
+	for i, r := range answered {
+		names[i] = r.Provider
+	}
+	sort.Strings(names)
+
+	// the lower-middle answer supplies the metadata (date, pair) of the consensus
+	result := answered[(len(answered)-1)/2]
+	result.Value = median
+	result.Provider = strings.Join(names, ",")
+	result.Spread = spread
+	result.Divergent = spread > f.Tolerance
+	result.Unconfirmed = len(answered) < 2
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package rate
+
+import (
+	"context"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsensusFetcher(t *testing.T) {
+	quote := func(v string) *stubFetcher {
+		return &stubFetcher{rate: domain.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal(v)}}
+	}
+	quoteOn := func(date, v string) *stubFetcher {
+		return &stubFetcher{rate: domain.Rate{EffectiveDate: date, Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal(v)}}
+	}
+
+	tests := []struct {
+		name            string
+		providers       []NamedFetcher
+		wantValue       string
+		wantDivergent   bool
+		wantUnconfirmed bool
+		wantErr         error
+	}{
+		{
+			name: "success: providers agree",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: quote("110.0")},
+				{Name: "b", Fetcher: quote("110.2")},
+				{Name: "c", Fetcher: quote("110.1")},
+			},
+			wantValue: "110.1",
+		},
+		{
+			name: "success: one provider is off",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: quote("110.0")},
+				{Name: "b", Fetcher: quote("120.0")},
+			},
+			wantValue:     "115.0",
+			wantDivergent: true,
+		},
+		{
+			name: "success: failed provider is left out",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: quote("110.0")},
+				{Name: "b", Fetcher: &stubFetcher{err: domain.ErrProviderUnavailable}},
+			},
+			wantValue:       "110.0",
+			wantUnconfirmed: true,
+		},
+		{
+			name: "success: answers for another date are left out",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: quoteOn("2026-03-18", "120.0")},
+				{Name: "b", Fetcher: quote("110.0")},
+				{Name: "c", Fetcher: quote("110.2")},
+			},
+			wantValue: "110.1",
+		},
+		{
+			name: "success: the latest date wins a tie",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: quoteOn("2026-03-18", "120.0")},
+				{Name: "b", Fetcher: quote("110.0")},
+			},
+			wantValue:       "110.0",
+			wantUnconfirmed: true,
+		},
+		{
+			name: "error: no provider answered",
+			providers: []NamedFetcher{
+				{Name: "a", Fetcher: &stubFetcher{err: domain.ErrProviderUnavailable}},
+				{Name: "b", Fetcher: &stubFetcher{err: domain.ErrQuotaExceeded}},
+			},
+			wantErr: domain.ErrProviderUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewConsensusFetcher(0.01, tt.providers...)
+			got, err := f.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, domain.MustParseDecimal(tt.wantValue), got.Value)
+			assert.Equal(t, "2026-03-19", got.EffectiveDate)
+			assert.Equal(t, tt.wantDivergent, got.Divergent)
+			assert.Equal(t, tt.wantUnconfirmed, got.Unconfirmed)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package rate
+
+import (
+	"context"
+	"slices"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// CombinedCurrencyLister lists every currency offered by at least one of several providers,
+// since the failover and consensus strategies can answer with any of them
+type CombinedCurrencyLister struct {
+	Listers []domain.CurrencyLister
+}
+
+// NewCombinedCurrencyLister creates a new CombinedCurrencyLister
+func NewCombinedCurrencyLister(listers ...domain.CurrencyLister) *CombinedCurrencyLister {
+	return &CombinedCurrencyLister{
+		Listers: listers,
+	}
+}
+
+// ListCurrencies returns the sorted union of the providers' lists.
+// It fails if any provider fails, since a partial union would reject currencies that are offered.
+func (l *CombinedCurrencyLister) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	var currencies []domain.Currency
+	for _, lister := range l.Listers {
+		listed, err := lister.ListCurrencies(ctx)
+		if err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, listed...)
+	}
+	slices.Sort(currencies)
+	return slices.Compact(currencies), nil
+}
@@ -0,0 +1,56 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLister struct{}
+
+func (failingLister) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	return nil, errors.New("symbols endpoint down")
+}
+
+func TestCombinedCurrencyLister(t *testing.T) {
+	tests := []struct {
+		name    string
+		listers []domain.CurrencyLister
+		want    []domain.Currency
+		wantErr bool
+	}{
+		{
+			name: "success: union of all providers",
+			listers: []domain.CurrencyLister{
+				&stubLister{currencies: []domain.Currency{"CAD", "JPY", "USD"}},
+				&stubLister{currencies: []domain.Currency{"CAD", "JPY", "THB"}},
+			},
+			want: []domain.Currency{"CAD", "JPY", "THB", "USD"},
+		},
+		{
+			name: "error: one provider cannot list",
+			listers: []domain.CurrencyLister{
+				&stubLister{currencies: []domain.Currency{"CAD", "JPY"}},
+				failingLister{},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewCombinedCurrencyLister(tt.listers...).ListCurrencies(context.Background())
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package rate
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// ProviderECB is the provider name recorded in rates fetched from the European Central Bank
+const ProviderECB = "ecb"
+
+const (
+	ecbDailyFeed  = "eurofxref-daily.xml"
+	ecbHist90Feed = "eurofxref-hist-90d.xml"
+	ecbHistFeed   = "eurofxref-hist.xml"
+)
+
+// ecb90DayWindow is how far back the 90-day feed is used instead of the multi-MB full history.
+// It stops short of 90 days so the fallback to an earlier business day stays inside the feed.
+const ecb90DayWindow = 80 * 24 * time.Hour
+
+// ecbPublicationHourUTC is the hour after which a business day's rates are out. The ECB
+// publishes around 16:00 CET, which is 14:00 or 15:00 UTC depending on daylight saving time.
+const ecbPublicationHourUTC = 16
+
+// ecbLateRecheck is how long a feed missing the expected latest day is kept, in case
+// the publication is late; on TARGET holidays it only adds a few downloads
+const ecbLateRecheck = 15 * time.Minute
+
+// ECBEnvelope is the eurofxref XML document. The daily feed holds one day,
+// the hist feed every business day since 1999, newest first.
+type ECBEnvelope struct {
+	Days []ECBDay `xml:"Cube>Cube"`
+}
+
+// ECBDay is the set of EUR-based reference rates published for one day
+type ECBDay struct {
+	Time  string    `xml:"time,attr"`
+	Rates []ECBRate `xml:"Cube"`
+}
+
+// ECBRate is a single EUR/currency reference rate
+type ECBRate struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
+
+// eurRates returns the day's rates keyed by currency, including EUR itself at 1
+func (d ECBDay) eurRates() (map[string]domain.Decimal, error) {
+	rates := make(map[string]domain.Decimal, len(d.Rates)+1)
+	rates["EUR"] = one
+	for _, r := range d.Rates {
+		value, err := domain.ParseDecimal(r.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rate for %s on %s: %w", domain.ErrMalformedResponse, r.Currency, d.Time, err)
+		}
+		rates[r.Currency] = value
+	}
+	return rates, nil
+}
+
+// ECBFetcher fetches the European Central Bank euro foreign exchange reference rates.
+// URL is the directory serving eurofxref-daily.xml, eurofxref-hist-90d.xml and eurofxref-hist.xml.
+// Parsed feeds are kept until the next publication, since they do not change in between.
+type ECBFetcher struct {
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration // per HTTP call; zero means no extra deadline beyond ctx
+	// Precision is applied to the EUR cross-rate division
+	Precision domain.Precision
+	now       func() time.Time
+
+	mu    sync.Mutex
+	feeds map[string]cachedFeed
+}
+
+// cachedFeed is a parsed feed and the time the next publication makes it stale
+type cachedFeed struct {
+	envelope  *ECBEnvelope
+	expiresAt time.Time
+}
+
+// NewECBFetcher creates a new ECBFetcher
+func NewECBFetcher(url string, client *http.Client, timeout time.Duration, precision domain.Precision) *ECBFetcher {
+	return &ECBFetcher{
+		URL:       url,
+		Client:    client,
+		Timeout:   timeout,
+		Precision: precision,
+		now:       time.Now,
+		feeds:     make(map[string]cachedFeed),
+	}
+}
+
+// FetchRate fetches base/target for date, crossed via EUR.
+// Dates the ECB did not publish (weekends, TARGET holidays) fall back to the last earlier business day.
+func (f *ECBFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	rates, err := f.FetchRates(ctx, date, base, []domain.Currency{target})
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	return rates[0], nil
+}
+
+// FetchRates fetches several targets against one base from a single feed download.
+// The daily feed answers the latest business day and later dates; older dates are read from
+// the 90-day feed when recent enough, and from the full hist feed otherwise.
+func (f *ECBFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	daily, err := f.fetchFeed(ctx, ecbDailyFeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rate: %w", err)
+	}
+	if len(daily.Days) == 0 {
+		return nil, fmt.Errorf("%w: daily feed has no rates", domain.ErrMalformedResponse)
+	}
+
+	day := daily.Days[0]
+	if date != "latest" && date < day.Time {
+		hist, err := f.fetchFeed(ctx, f.histFeedFor(date))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch rate: %w", err)
+		}
+		var ok bool
+		if day, ok = latestOnOrBefore(hist.Days, date); !ok {
+			return nil, fmt.Errorf("%w: no ECB reference rate on or before %s", domain.ErrRateNotFound, date)
+		}
+	}
+
+	eurRates, err := day.eurRates()
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]domain.Rate, 0, len(targets))
+	for _, target := range targets {
+		rateValue, err := crossViaEUR(eurRates, base, target, f.Precision)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: day.Time,
+			RequestedDate: date,
+			Provider:      ProviderECB,
+		})
+	}
+
+	return rates, nil
+}
+
+// FetchRange fetches daily base/target rates between from and to from the 90-day or full hist feed.
+// Only business days are published, so weekends and holidays are absent from the result.
+func (f *ECBFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	hist, err := f.fetchFeed(ctx, f.histFeedFor(from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rate range: %w", err)
+	}
+
+	series := make(map[string]ECBDay)
+	for _, day := range hist.Days {
+		if day.Time >= from && day.Time <= to {
+			series[day.Time] = day
+		}
+	}
+
+	rates := make([]domain.Rate, 0, len(series))
+	for _, date := range sortedDates(series) {
+		eurRates, err := series[date].eurRates()
+		if err != nil {
+			return nil, err
+		}
+		rateValue, err := crossViaEUR(eurRates, base, target, f.Precision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate rate for %s: %w", date, err)
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: date,
+			Provider:      ProviderECB,
+		})
+	}
+
+	return rates, nil
+}
+
+// histFeedFor picks the smallest hist feed that covers date
+func (f *ECBFetcher) histFeedFor(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil || f.now().Sub(day) > ecb90DayWindow {
+		return ecbHistFeed
+	}
+	return ecbHist90Feed
+}
+
+// fetchFeed returns one eurofxref XML feed, downloading and parsing it only when the
+// cached copy predates the latest publication
+func (f *ECBFetcher) fetchFeed(ctx context.Context, feed string) (*ECBEnvelope, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if cached, ok := f.feeds[feed]; ok && now.Before(cached.expiresAt) {
+		return cached.envelope, nil
+	}
+
+	data, err := f.downloadFeed(ctx, feed)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := nextECBPublication(now)
+	if latestDay(data.Days) < lastECBPublication(now) {
+		expiresAt = now.Add(ecbLateRecheck)
+	}
+	f.feeds[feed] = cachedFeed{envelope: data, expiresAt: expiresAt}
+	return data, nil
+}
+
+// downloadFeed downloads and parses one eurofxref XML feed
+func (f *ECBFetcher) downloadFeed(ctx context.Context, feed string) (*ECBEnvelope, error) {
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, f.URL+feed)
+	if err != nil {
+		return nil, err
+	}
+
+	var data ECBEnvelope
+	if err := xml.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse XML: %w", domain.ErrMalformedResponse, err)
+	}
+	return &data, nil
+}
+
+// nextECBPublication returns when the first weekday's rates after now are out
+func nextECBPublication(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), ecbPublicationHourUTC, 0, 0, 0, time.UTC)
+	for !next.After(now) || isWeekend(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// lastECBPublication returns the latest weekday whose rates are out at now, as YYYY-MM-DD
+func lastECBPublication(now time.Time) string {
+	now = now.UTC()
+	last := time.Date(now.Year(), now.Month(), now.Day(), ecbPublicationHourUTC, 0, 0, 0, time.UTC)
+	for last.After(now) || isWeekend(last) {
+		last = last.AddDate(0, 0, -1)
+	}
+	return last.Format("2006-01-02")
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// latestDay returns the most recent date in days
+func latestDay(days []ECBDay) string {
+	latest := ""
+	for _, day := range days {
+		latest = max(latest, day.Time)
+	}
+	return latest
+}
+
+// latestOnOrBefore returns the most recent day published on or before date
+func latestOnOrBefore(days []ECBDay, date string) (ECBDay, bool) {
+	var found ECBDay
+	ok := false
+	for _, day := range days {
+		if day.Time <= date && (!ok || day.Time > found.Time) {
+			found, ok = day, true
+		}
+	}
+	return found, ok
+}
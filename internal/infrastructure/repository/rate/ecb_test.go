@@ -0,0 +1,157 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ecbDailyXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-03-20">
+			<Cube currency="USD" rate="1.0800"/>
+			<Cube currency="JPY" rate="162.00"/>
+			<Cube currency="CAD" rate="1.5000"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const ecbHistXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2026-03-20"><Cube currency="JPY" rate="162.00"/><Cube currency="CAD" rate="1.5000"/></Cube>
+		<Cube time="2026-03-17"><Cube currency="JPY" rate="161.00"/><Cube currency="CAD" rate="1.4000"/></Cube>
+		<Cube time="2026-03-13"><Cube currency="JPY" rate="160.00"/><Cube currency="CAD" rate="1.6000"/></Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func newECBServer(t *testing.T) *httptest.Server {
+	srv, _ := newCountingECBServer(t)
+	return srv
+}
+
+// newCountingECBServer serves the feeds and counts the downloads of each path
+func newCountingECBServer(t *testing.T) (*httptest.Server, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	downloads := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		downloads[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/eurofxref-daily.xml":
+			_, _ = w.Write([]byte(ecbDailyXML))
+		case "/eurofxref-hist.xml", "/eurofxref-hist-90d.xml":
+			_, _ = w.Write([]byte(ecbHistXML))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, downloads
+}
+
+func TestECBFetchRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		date      string
+		base      domain.Currency
+		target    domain.Currency
+		wantDate  string
+		wantValue string
+		wantErr   error
+	}{
+		{name: "success: latest day from the daily feed", date: "2026-03-20", base: "CAD", target: "JPY", wantDate: "2026-03-20", wantValue: "108"},
+		{name: "success: weekend after the daily feed", date: "2026-03-22", base: "CAD", target: "JPY", wantDate: "2026-03-20", wantValue: "108"},
+		{name: "success: EUR base needs no cross", date: "2026-03-20", base: "EUR", target: "USD", wantDate: "2026-03-20", wantValue: "1.08"},
+		{name: "success: historical day from the hist feed", date: "2026-03-17", base: "CAD", target: "JPY", wantDate: "2026-03-17", wantValue: "115"},
+		{name: "success: non-publishing day uses the previous business day", date: "2026-03-15", base: "CAD", target: "JPY", wantDate: "2026-03-13", wantValue: "100"},
+		{name: "error: before the first published day", date: "1998-12-31", base: "CAD", target: "JPY", wantErr: domain.ErrRateNotFound},
+		{name: "error: currency not published", date: "2026-03-20", base: "CAD", target: "XAU", wantErr: domain.ErrUnsupportedCurrency},
+	}
+
+	f := NewECBFetcher(newECBServer(t).URL+"/", nil, 0, domain.DefaultPrecision)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.FetchRate(context.Background(), tt.date, tt.base, tt.target)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDate, got.EffectiveDate)
+			assert.Equal(t, tt.wantValue, got.Value.String())
+			assert.Equal(t, ProviderECB, got.Provider)
+		})
+	}
+}
+
+func TestECBFetchRange(t *testing.T) {
+	f := NewECBFetcher(newECBServer(t).URL+"/", nil, 0, domain.DefaultPrecision)
+
+	got, err := f.FetchRange(context.Background(), "2026-03-13", "2026-03-17", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Rate{
+		{EffectiveDate: "2026-03-13", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("100"), Provider: ProviderECB},
+		{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("115"), Provider: ProviderECB},
+	}, got)
+}
+
+func TestECBFetcher_FeedsAndCache(t *testing.T) {
+	srv, downloads := newCountingECBServer(t)
+	f := NewECBFetcher(srv.URL+"/", nil, 0, domain.DefaultPrecision)
+	clock := time.Date(2026, 3, 21, 10, 0, 0, 0, time.UTC) // Saturday after the 2026-03-20 publication
+	f.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	// recent dates are read from the 90-day feed, and feeds are parsed once
+	for range 2 {
+		_, err := f.FetchRate(ctx, "2026-03-17", "CAD", "JPY")
+		assert.NoError(t, err)
+	}
+	_, err := f.FetchRange(ctx, "2026-03-13", "2026-03-17", "CAD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"/eurofxref-daily.xml": 1, "/eurofxref-hist-90d.xml": 1}, downloads)
+
+	// older dates need the full history
+	_, err = f.FetchRate(ctx, "2025-12-01", "CAD", "JPY")
+	assert.ErrorIs(t, err, domain.ErrRateNotFound)
+	assert.Equal(t, 1, downloads["/eurofxref-hist.xml"])
+
+	// Monday's publication makes the cached feeds stale
+	clock = time.Date(2026, 3, 23, 17, 0, 0, 0, time.UTC)
+	_, err = f.FetchRate(ctx, "2026-03-17", "CAD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, downloads["/eurofxref-daily.xml"])
+	assert.Equal(t, 2, downloads["/eurofxref-hist-90d.xml"])
+}
+
+func TestNextECBPublication(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "weekday before publication", now: time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 19, 16, 0, 0, 0, time.UTC)},
+		{name: "weekday after publication", now: time.Date(2026, 3, 19, 17, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 20, 16, 0, 0, 0, time.UTC)},
+		{name: "friday after publication", now: time.Date(2026, 3, 20, 17, 0, 0, 0, time.UTC), want: time.Date(2026, 3, 23, 16, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nextECBPublication(tt.now))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// NamedFetcher pairs a RateFetcher with the provider name reported in rate.Rate.Provider
+type NamedFetcher struct {
+	Name    string
+	Fetcher domain.RateFetcher
+}
+
+// FailoverFetcher tries each provider in order and moves on to the next one
+// only when the current provider returns a retryable error.
+type FailoverFetcher struct {
+	Providers []NamedFetcher
+}
+
+// NewFailoverFetcher creates a new FailoverFetcher with providers in priority order
+func NewFailoverFetcher(providers ...NamedFetcher) *FailoverFetcher {
+	return &FailoverFetcher{
+		Providers: providers,
+	}
+}
+
+// FetchRate returns the rate from the first provider that answers
+func (f *FailoverFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	rates, err := f.try(ctx, func(p NamedFetcher) ([]domain.Rate, error) {
+		r, err := p.Fetcher.FetchRate(ctx, date, base, target)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.Rate{r}, nil
+	})
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	return rates[0], nil
+}
+
+// FetchRates returns the rates of every target from the first provider that answers.
+// Providers without batch support are queried one target at a time.
+func (f *FailoverFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	return f.try(ctx, func(p NamedFetcher) ([]domain.Rate, error) {
+		if batch, ok := p.Fetcher.(domain.BatchFetcher); ok {
+			return batch.FetchRates(ctx, date, base, targets)
+		}
+		rates := make([]domain.Rate, 0, len(targets))
+		for _, target := range targets {
+			r, err := p.Fetcher.FetchRate(ctx, date, base, target)
+			if err != nil {
+				return nil, err
+			}
+			rates = append(rates, r)
+		}
+		return rates, nil
+	})
+}
+
+// FetchRange returns the series from the first provider that answers
+func (f *FailoverFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	return f.try(ctx, func(p NamedFetcher) ([]domain.Rate, error) {
+		return fetchRange(ctx, p.Fetcher, from, to, base, target)
+	})
+}
+
+// try runs fetch against each provider until one succeeds or returns a non-retryable error
+func (f *FailoverFetcher) try(ctx context.Context, fetch func(NamedFetcher) ([]domain.Rate, error)) ([]domain.Rate, error) {
+	if len(f.Providers) == 0 {
+		return nil, fmt.Errorf("%w: no providers configured", domain.ErrProviderUnavailable)
+	}
+
+	var errs []error
+	for _, p := range f.Providers {
+		rates, err := fetch(p)
+		if err == nil {
+			for i := range rates {
+				rates[i].Provider = p.Name
+			}
+			return rates, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		if ctx.Err() != nil || !domain.IsRetryable(err) {
+			break
+		}
+		log.Printf("rate provider %s failed, trying next provider: %v", p.Name, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
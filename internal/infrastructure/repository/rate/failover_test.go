@@ -0,0 +1,75 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubFetcher struct {
+	rate  domain.Rate
+	err   error
+	calls int
+}
+
+func (s *stubFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	s.calls++
+	return s.rate, s.err
+}
+
+func TestFailoverFetcher(t *testing.T) {
+	answer := domain.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22")}
+
+	tests := []struct {
+		name         string
+		primaryErr   error
+		wantProvider string
+		wantErr      error
+		wantBackup   int
+	}{
+		{name: "success: primary answers", wantProvider: "primary"},
+		{
+			name:         "success: primary unavailable, backup answers",
+			primaryErr:   fmt.Errorf("%w: HTTP error: 503", domain.ErrProviderUnavailable),
+			wantProvider: "backup",
+			wantBackup:   1,
+		},
+		{
+			name:         "success: primary over quota, backup answers",
+			primaryErr:   domain.ErrQuotaExceeded,
+			wantProvider: "backup",
+			wantBackup:   1,
+		},
+		{
+			name:       "error: non-retryable error stops the chain",
+			primaryErr: domain.ErrUnsupportedCurrency,
+			wantErr:    domain.ErrUnsupportedCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubFetcher{rate: answer, err: tt.primaryErr}
+			backup := &stubFetcher{rate: answer}
+			f := NewFailoverFetcher(
+				NamedFetcher{Name: "primary", Fetcher: primary},
+				NamedFetcher{Name: "backup", Fetcher: backup},
+			)
+
+			got, err := f.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantProvider, got.Provider)
+				assert.Equal(t, answer.Value, got.Value)
+			}
+			assert.Equal(t, tt.wantBackup, backup.calls)
+		})
+	}
+}
@@ -1,76 +1,233 @@
 package rate
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	neturl "net/url"
+	"time"
 
 	domain "yenup/internal/domain/rate"
 )
 
+// ProviderExchangeRates is the provider name recorded in rates fetched from ExchangeRates API
+const ProviderExchangeRates = "exchangerates"
+
 // ExchangeRatesResponse is the response structure for ExchangeRates API
 type ExchangeRatesResponse struct {
-	Success bool               `json:"success"`
-	Base    string             `json:"base"`
-	Date    string             `json:"date"`
-	Rates   map[string]float64 `json:"rates"`
+	Success bool                      `json:"success"`
+	Base    string                    `json:"base"`
+	Date    string                    `json:"date"`
+	Rates   map[string]domain.Decimal `json:"rates"`
+	Error   *ExchangeRatesError       `json:"error,omitempty"`
+}
+
+// ExchangeRatesTimeseriesResponse is the response structure for the ExchangeRates timeseries endpoint
+type ExchangeRatesTimeseriesResponse struct {
+	Success   bool                                 `json:"success"`
+	Base      string                               `json:"base"`
+	StartDate string                               `json:"start_date"`
+	EndDate   string                               `json:"end_date"`
+	Rates     map[string]map[string]domain.Decimal `json:"rates"`
+	Error     *ExchangeRatesError                  `json:"error,omitempty"`
+}
+
+// ExchangeRatesSymbolsResponse is the response structure for the ExchangeRates symbols endpoint
+type ExchangeRatesSymbolsResponse struct {
+	Success bool                `json:"success"`
+	Symbols map[string]string   `json:"symbols"`
+	Error   *ExchangeRatesError `json:"error,omitempty"`
+}
+
+// ExchangeRatesError is the error object ExchangeRates API returns with HTTP 200 and success=false
+type ExchangeRatesError struct {
+	Code int    `json:"code"`
+	Type string `json:"type"`
+	Info string `json:"info"`
+}
+
+// domainError maps an ExchangeRates API error object to the matching domain error
+func (e *ExchangeRatesError) domainError() error {
+	var sentinel error
+	switch e.Code {
+	case 104, 429: // usage_limit_reached, too_many_requests
+		sentinel = domain.ErrQuotaExceeded
+	case 106, 302: // no_rates_available, invalid_date
+		sentinel = domain.ErrRateNotFound
+	case 201, 202: // invalid_base_currency, invalid_currency_codes
+		sentinel = domain.ErrUnsupportedCurrency
+	default:
+		sentinel = domain.ErrProviderUnavailable
+	}
+	return fmt.Errorf("%w: %d %s", sentinel, e.Code, e.Type)
 }
 
 // ExchangeRatesFetcher fetches rates from the ExchangeRates API (requires API key)
 type ExchangeRatesFetcher struct {
-	APIKey string
-	URL    string
+	APIKey  string
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration // per HTTP call; zero means no extra deadline beyond ctx
+	// Precision is applied to the EUR cross-rate division
+	Precision domain.Precision
 }
 
 // NewExchangeRatesFetcher creates a new ExchangeRatesFetcher
-func NewExchangeRatesFetcher(apiKey, url string) *ExchangeRatesFetcher {
+func NewExchangeRatesFetcher(apiKey, url string, client *http.Client, timeout time.Duration, precision domain.Precision) *ExchangeRatesFetcher {
 	return &ExchangeRatesFetcher{
-		APIKey: apiKey,
-		URL:    url,
+		APIKey:    apiKey,
+		URL:       url,
+		Client:    client,
+		Timeout:   timeout,
+		Precision: precision,
 	}
 }
 
 // FetchRate fetches the exchange rate for base/target by using EUR as intermediate
 // Since free plan only supports EUR as base, we calculate:
 // base/target = EUR/target ÷ EUR/base
-func (f *ExchangeRatesFetcher) FetchRate(date, base, target string) (domain.Rate, error) {
+func (f *ExchangeRatesFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	rates, err := f.FetchRates(ctx, date, base, []domain.Currency{target})
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	return rates[0], nil
+}
+
+// FetchRates fetches the rates of several targets against one base in a single request.
+// Every target is crossed via EUR the same way as FetchRate. The result follows the order of targets.
+func (f *ExchangeRatesFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
 	url := fmt.Sprintf(
-		"%s%s?base=EUR&symbols=%s,%s&access_key=%s",
+		"%s%s?base=EUR&symbols=%s&access_key=%s",
 		f.URL,
 		neturl.PathEscape(date),
-		neturl.QueryEscape(base),
-		neturl.QueryEscape(target),
+		joinSymbols(append([]domain.Currency{base}, targets...)),
 		f.APIKey,
 	)
 
-	body, err := doGet(url)
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
 	if err != nil {
-		return domain.Rate{}, fmt.Errorf("failed to fetch rate: %w", err)
+		return nil, fmt.Errorf("failed to fetch rate: %w", err)
 	}
 
 	var data ExchangeRatesResponse
 	if err := json.Unmarshal(body, &data); err != nil {
-		return domain.Rate{}, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
+	}
+	if !data.Success && data.Error != nil {
+		return nil, data.Error.domainError()
 	}
 
-	// Get EUR/base and EUR/target rates
-	eurToBase := data.Rates[base]
-	eurToTarget := data.Rates[target]
+	// the API answers with the last published day, which differs from date when markets were closed
+	effectiveDate := data.Date
+	if effectiveDate == "" {
+		effectiveDate = date
+	}
 
-	if eurToBase == 0 {
-		return domain.Rate{}, fmt.Errorf("rate for %s not found in response", base)
+	rates := make([]domain.Rate, 0, len(targets))
+	for _, target := range targets {
+		rateValue, err := crossViaEUR(data.Rates, base, target, f.Precision)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: effectiveDate,
+			RequestedDate: date,
+			Provider:      ProviderExchangeRates,
+		})
 	}
-	if eurToTarget == 0 {
-		return domain.Rate{}, fmt.Errorf("rate for %s not found in response", target)
+
+	return rates, nil
+}
+
+// FetchRange fetches daily base/target rates between from and to using the timeseries endpoint.
+// The EUR cross-rate calculation from FetchRate is applied to each day.
+func (f *ExchangeRatesFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	url := fmt.Sprintf(
+		"%stimeseries?start_date=%s&end_date=%s&base=EUR&symbols=%s,%s&access_key=%s",
+		f.URL,
+		neturl.QueryEscape(from),
+		neturl.QueryEscape(to),
+		neturl.QueryEscape(string(base)),
+		neturl.QueryEscape(string(target)),
+		f.APIKey,
+	)
+
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rate range: %w", err)
+	}
+
+	var data ExchangeRatesTimeseriesResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
+	}
+	if !data.Success && data.Error != nil {
+		return nil, data.Error.domainError()
+	}
+
+	rates := make([]domain.Rate, 0, len(data.Rates))
+	for _, date := range sortedDates(data.Rates) {
+		rateValue, err := crossViaEUR(data.Rates[date], base, target, f.Precision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate rate for %s: %w", date, err)
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: date,
+			Provider:      ProviderExchangeRates,
+		})
+	}
+
+	return rates, nil
+}
+
+// ListCurrencies returns the currencies ExchangeRates API quotes, from the /symbols endpoint
+func (f *ExchangeRatesFetcher) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	url := fmt.Sprintf("%ssymbols?access_key=%s", f.URL, f.APIKey)
+
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch currencies: %w", err)
+	}
+
+	var data ExchangeRatesSymbolsResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
 	}
+	if !data.Success && data.Error != nil {
+		return nil, data.Error.domainError()
+	}
+	return currencyCodes(data.Symbols)
+}
+
+// crossViaEUR calculates base/target from EUR-based quotes, rounded to precision:
+// base/target = EUR/target ÷ EUR/base
+func crossViaEUR(eurRates map[string]domain.Decimal, base, target domain.Currency, precision domain.Precision) (domain.Decimal, error) {
+	eurToBase := eurRates[string(base)]
+	eurToTarget := eurRates[string(target)]
 
-	// Calculate cross rate: base/target = EUR/target ÷ EUR/base
-	rateValue := eurToTarget / eurToBase
+	if eurToBase.IsZero() {
+		return domain.Decimal{}, fmt.Errorf("%w: rate for %s not found in response", domain.ErrUnsupportedCurrency, base)
+	}
+	if eurToTarget.IsZero() {
+		return domain.Decimal{}, fmt.Errorf("%w: rate for %s not found in response", domain.ErrUnsupportedCurrency, target)
+	}
 
-	return domain.Rate{
-		Base:   base,
-		Target: target,
-		Value:  rateValue,
-		Date:   date,
-	}, nil
+	return eurToTarget.Div(eurToBase, precision)
 }
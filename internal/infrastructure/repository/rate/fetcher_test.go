@@ -0,0 +1,73 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRatesFetchRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/timeseries", r.URL.Path)
+		assert.Equal(t, "2026-03-16", r.URL.Query().Get("start_date"))
+		assert.Equal(t, "2026-03-17", r.URL.Query().Get("end_date"))
+		_, _ = w.Write([]byte(`{"success":true,"timeseries":true,"base":"EUR",` +
+			`"rates":{"2026-03-17":{"CAD":1.5,"JPY":165},"2026-03-16":{"CAD":1.6,"JPY":176}}}`))
+	}))
+	defer srv.Close()
+
+	f := NewExchangeRatesFetcher("key", srv.URL+"/", srv.Client(), 0, domain.DefaultPrecision)
+	got, err := f.FetchRange(context.Background(), "2026-03-16", "2026-03-17", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "2026-03-16", got[0].EffectiveDate)
+	assert.Equal(t, domain.MustParseDecimal("110"), got[0].Value)
+	assert.Equal(t, "2026-03-17", got[1].EffectiveDate)
+	assert.Equal(t, domain.MustParseDecimal("110"), got[1].Value)
+}
+
+func TestExchangeRatesFetchRate_ProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"error":{"code":104,"type":"usage_limit_reached"}}`))
+	}))
+	defer srv.Close()
+
+	f := NewExchangeRatesFetcher("key", srv.URL+"/", srv.Client(), 0, domain.DefaultPrecision)
+	_, err := f.FetchRate(context.Background(), "2026-03-17", "CAD", "JPY")
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+}
+
+func TestExchangeRatesFetchRate_MarketClosed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2026-03-21", r.URL.Path)
+		_, _ = w.Write([]byte(`{"success":true,"base":"EUR","date":"2026-03-20","rates":{"CAD":1.5,"JPY":165}}`))
+	}))
+	defer srv.Close()
+
+	f := NewExchangeRatesFetcher("key", srv.URL+"/", srv.Client(), 0, domain.DefaultPrecision)
+	got, err := f.FetchRate(context.Background(), "2026-03-21", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-20", got.EffectiveDate)
+	assert.Equal(t, "2026-03-21", got.RequestedDate)
+}
+
+func TestExchangeRatesListCurrencies_UnsuccessfulWithoutError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/symbols", r.URL.Path)
+		_, _ = w.Write([]byte(`{"success":false}`))
+	}))
+	defer srv.Close()
+
+	f := NewExchangeRatesFetcher("key", srv.URL+"/", srv.Client(), 0, domain.DefaultPrecision)
+	_, err := f.ListCurrencies(context.Background())
+
+	assert.ErrorIs(t, err, domain.ErrMalformedResponse)
+}
@@ -0,0 +1,195 @@
+package rate
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// ProviderFile is the provider name recorded in rates read from a local file
+const ProviderFile = "file"
+
+// FileFetcher serves rates from a local CSV or JSON file, for demos, development and CI.
+// A .json file holds an array of {"date", "base", "target", "value"} objects;
+// any other file is read as CSV with a date,base,target,value header.
+// The file is re-read whenever its modification time or size changes.
+type FileFetcher struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	rates   map[string]map[string]domain.Rate // pair -> date -> rate
+}
+
+// NewFileFetcher creates a new FileFetcher
+func NewFileFetcher(path string) *FileFetcher {
+	return &FileFetcher{
+		Path: path,
+	}
+}
+
+// FetchRate returns the rate for date ("latest" for the newest one).
+// Like the other providers, it falls back to the latest date listed before date when date
+// itself is not listed, and fails with domain.ErrRateNotFound when the file starts after date.
+func (f *FileFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	series, err := f.series(base, target)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+
+	found := ""
+	for listed := range series {
+		if (date == "latest" || listed <= date) && listed > found {
+			found = listed
+		}
+	}
+	if found == "" {
+		return domain.Rate{}, fmt.Errorf("%w: no %s/%s rate on or before %s in %s", domain.ErrRateNotFound, base, target, date, f.Path)
+	}
+
+	r := series[found]
+	r.RequestedDate = date
+	return r, nil
+}
+
+// FetchRange returns the rates listed between from and to, in date order
+func (f *FileFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	series, err := f.series(base, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []domain.Rate
+	for _, date := range sortedDates(series) {
+		if date >= from && date <= to {
+			rates = append(rates, series[date])
+		}
+	}
+	return rates, nil
+}
+
+// series returns the rates of one pair keyed by date, reloading the file first if it changed
+func (f *FileFetcher) series(base, target domain.Currency) (map[string]domain.Rate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	series, ok := f.rates[pairKey(base, target)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s not listed in %s", domain.ErrUnsupportedCurrency, base, target, f.Path)
+	}
+	return series, nil
+}
+
+// reload parses the file when it changed since the last read. The caller holds f.mu.
+func (f *FileFetcher) reload() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("%w: failed to stat rate file: %w", domain.ErrProviderUnavailable, err)
+	}
+	if f.rates != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return nil
+	}
+
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return fmt.Errorf("%w: failed to open rate file: %w", domain.ErrProviderUnavailable, err)
+	}
+	defer file.Close()
+
+	var rates []domain.Rate
+	if strings.EqualFold(filepath.Ext(f.Path), ".json") {
+		rates, err = parseRatesJSON(file)
+	} else {
+		rates, err = parseRatesCSV(file)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", domain.ErrMalformedResponse, f.Path, err)
+	}
+
+	byPair := make(map[string]map[string]domain.Rate)
+	for _, r := range rates {
+		key := pairKey(r.Base, r.Target)
+		if byPair[key] == nil {
+			byPair[key] = make(map[string]domain.Rate)
+		}
+		r.Provider = ProviderFile
+		byPair[key][r.EffectiveDate] = r
+	}
+
+	f.rates = byPair
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return nil
+}
+
+func parseRatesJSON(r io.Reader) ([]domain.Rate, error) {
+	var rates []domain.Rate
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	for i, rate := range rates {
+		if rate.EffectiveDate == "" || rate.Base == "" || rate.Target == "" {
+			return nil, fmt.Errorf("entry %d: date, base and target are required", i)
+		}
+	}
+	return rates, nil
+}
+
+func parseRatesCSV(r io.Reader) ([]domain.Rate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	if strings.Join(header, ",") != "date,base,target,value" {
+		return nil, fmt.Errorf("unexpected CSV header %q, want date,base,target,value", strings.Join(header, ","))
+	}
+
+	var rates []domain.Rate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		base, err := domain.ParseCurrency(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		target, err := domain.ParseCurrency(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		value, err := domain.ParseDecimal(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, domain.Rate{EffectiveDate: record[0], Base: base, Target: target, Value: value})
+	}
+	return rates, nil
+}
+
+func pairKey(base, target domain.Currency) string {
+	return string(base) + "/" + string(target)
+}
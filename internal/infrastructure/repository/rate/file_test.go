@@ -0,0 +1,109 @@
+package rate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFetcherFetchRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		date     string
+		target   domain.Currency
+		wantRate domain.Rate
+		wantErr  error
+	}{
+		{
+			name:     "success: CSV exact date",
+			file:     "rates.csv",
+			content:  "date,base,target,value\n2026-03-19,CAD,JPY,110.22\n2026-03-20,cad,jpy,110.50\n",
+			date:     "2026-03-19",
+			target:   "JPY",
+			wantRate: domain.Rate{EffectiveDate: "2026-03-19", RequestedDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22"), Provider: ProviderFile},
+		},
+		{
+			name:     "success: JSON falls back to the latest date",
+			file:     "rates.json",
+			content:  `[{"date":"2026-03-20","base":"CAD","target":"JPY","value":110.5},{"date":"2026-03-19","base":"CAD","target":"JPY","value":110.22}]`,
+			date:     "2026-03-22",
+			target:   "JPY",
+			wantRate: domain.Rate{EffectiveDate: "2026-03-20", RequestedDate: "2026-03-22", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.5"), Provider: ProviderFile},
+		},
+		{
+			name:     "success: a missing date falls back to the previous listed date",
+			file:     "rates.csv",
+			content:  "date,base,target,value\n2026-03-13,CAD,JPY,109.80\n2026-03-16,CAD,JPY,110.22\n2026-03-20,CAD,JPY,110.50\n",
+			date:     "2026-03-15",
+			target:   "JPY",
+			wantRate: domain.Rate{EffectiveDate: "2026-03-13", RequestedDate: "2026-03-15", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("109.80"), Provider: ProviderFile},
+		},
+		{
+			name:    "error: date before the first listed date",
+			file:    "rates.csv",
+			content: "date,base,target,value\n2026-03-19,CAD,JPY,110.22\n",
+			date:    "2026-03-18",
+			target:  "JPY",
+			wantErr: domain.ErrRateNotFound,
+		},
+		{
+			name:    "error: pair not listed",
+			file:    "rates.csv",
+			content: "date,base,target,value\n2026-03-19,CAD,JPY,110.22\n",
+			date:    "2026-03-19",
+			target:  "USD",
+			wantErr: domain.ErrUnsupportedCurrency,
+		},
+		{
+			name:    "error: invalid value",
+			file:    "rates.csv",
+			content: "date,base,target,value\n2026-03-19,CAD,JPY,abc\n",
+			date:    "2026-03-19",
+			target:  "JPY",
+			wantErr: domain.ErrMalformedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			got, err := NewFileFetcher(path).FetchRate(context.Background(), tt.date, "CAD", tt.target)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, got)
+		})
+	}
+}
+
+func TestFileFetcherReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("date,base,target,value\n2026-03-19,CAD,JPY,110.22\n"), 0o644))
+	f := NewFileFetcher(path)
+
+	got, err := f.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, "110.22", got.Value.String())
+
+	assert.NoError(t, os.WriteFile(path, []byte("date,base,target,value\n2026-03-19,CAD,JPY,111.00\n"), 0o644))
+	// make the change visible even on filesystems with coarse timestamps
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, later, later))
+
+	got, err = f.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, "111", got.Value.String())
+}
@@ -1,82 +1,180 @@
 package rate
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	neturl "net/url"
-	"strings"
+	"time"
 
 	domain "yenup/internal/domain/rate"
 )
 
+// ProviderFrankfurter is the provider name recorded in rates fetched from Frankfurter
+const ProviderFrankfurter = "frankfurter"
+
 // FrankfurterResponse is the response structure for Frankfurter API
 type FrankfurterResponse struct {
-	Amount float64            `json:"amount"`
-	Base   string             `json:"base"`
-	Date   string             `json:"date"`
-	Rates  map[string]float64 `json:"rates"`
+	Amount float64                   `json:"amount"`
+	Base   string                    `json:"base"`
+	Date   string                    `json:"date"`
+	Rates  map[string]domain.Decimal `json:"rates"`
+}
+
+// FrankfurterTimeseriesResponse is the response structure for the Frankfurter time series endpoint
+type FrankfurterTimeseriesResponse struct {
+	Amount    float64                              `json:"amount"`
+	Base      string                               `json:"base"`
+	StartDate string                               `json:"start_date"`
+	EndDate   string                               `json:"end_date"`
+	Rates     map[string]map[string]domain.Decimal `json:"rates"`
 }
 
 // FrankfurterFetcher fetches rates from the Frankfurter API (free, no API key required)
 type FrankfurterFetcher struct {
-	URL string
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration // per HTTP call; zero means no extra deadline beyond ctx
 }
 
 // NewFrankfurterFetcher creates a new FrankfurterFetcher
-func NewFrankfurterFetcher(url string) *FrankfurterFetcher {
+func NewFrankfurterFetcher(url string, client *http.Client, timeout time.Duration) *FrankfurterFetcher {
 	return &FrankfurterFetcher{
-		URL: url,
+		URL:     url,
+		Client:  client,
+		Timeout: timeout,
 	}
 }
 
 // FetchRate fetches the exchange rate for the given date, base, and target currencies
 // If the specific date is not available, it falls back to the latest available date
-func (f *FrankfurterFetcher) FetchRate(date, base, target string) (domain.Rate, error) {
+func (f *FrankfurterFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	rates, err := f.FetchRates(ctx, date, base, []domain.Currency{target})
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	return rates[0], nil
+}
+
+// FetchRates fetches the rates of several targets against one base in a single request.
+// The result follows the order of targets. Like FetchRate, it falls back to the latest available date.
+func (f *FrankfurterFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
 	url := fmt.Sprintf("%s%s?from=%s&to=%s",
 		f.URL,
 		neturl.PathEscape(date),
-		neturl.QueryEscape(base),
-		neturl.QueryEscape(target),
+		neturl.QueryEscape(string(base)),
+		joinSymbols(targets),
 	)
 
-	rate, err := f.fetchFromURL(url, base, target)
+	rates, err := f.fetchFromURL(ctx, url, date, base, targets)
 	if err != nil {
 		// If 404 (not found), try fetching the latest available data
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, domain.ErrRateNotFound) {
 			latestUrl := fmt.Sprintf("%slatest?from=%s&to=%s",
 				f.URL,
-				neturl.QueryEscape(base),
-				neturl.QueryEscape(target),
+				neturl.QueryEscape(string(base)),
+				joinSymbols(targets),
 			)
-			return f.fetchFromURL(latestUrl, base, target)
+			return f.fetchFromURL(ctx, latestUrl, date, base, targets)
 		}
-		return domain.Rate{}, err
+		return nil, err
 	}
 
-	return rate, nil
+	return rates, nil
 }
 
-// fetchFromURL fetches rate data from a given URL and parses the response
-func (f *FrankfurterFetcher) fetchFromURL(url string, base string, target string) (domain.Rate, error) {
-	body, err := doGet(url)
+// fetchFromURL fetches rate data from a given URL and parses the response.
+// requestedDate is recorded next to the date Frankfurter actually answered for.
+func (f *FrankfurterFetcher) fetchFromURL(ctx context.Context, url, requestedDate string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
 	if err != nil {
-		return domain.Rate{}, err
+		return nil, err
 	}
 
 	var data FrankfurterResponse
 	if err := json.Unmarshal(body, &data); err != nil {
-		return domain.Rate{}, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
+	}
+
+	rates := make([]domain.Rate, 0, len(targets))
+	for _, target := range targets {
+		rateValue := data.Rates[string(target)]
+		if rateValue.IsZero() {
+			return nil, fmt.Errorf("%w: rate for %s not found in response", domain.ErrUnsupportedCurrency, target)
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: data.Date,
+			RequestedDate: requestedDate,
+			Provider:      ProviderFrankfurter,
+		})
+	}
+
+	return rates, nil
+}
+
+// FetchRange fetches daily rates between from and to using the /YYYY-MM-DD..YYYY-MM-DD endpoint.
+// Frankfurter only publishes business days, so weekends and holidays are absent from the result.
+func (f *FrankfurterFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	url := fmt.Sprintf("%s%s..%s?from=%s&to=%s",
+		f.URL,
+		neturl.PathEscape(from),
+		neturl.PathEscape(to),
+		neturl.QueryEscape(string(base)),
+		neturl.QueryEscape(string(target)),
+	)
+
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var data FrankfurterTimeseriesResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
 	}
 
-	rateValue := data.Rates[target]
-	if rateValue == 0 {
-		return domain.Rate{}, fmt.Errorf("rate for %s not found in response", target)
+	rates := make([]domain.Rate, 0, len(data.Rates))
+	for _, date := range sortedDates(data.Rates) {
+		rateValue := data.Rates[date][string(target)]
+		if rateValue.IsZero() {
+			return nil, fmt.Errorf("%w: rate for %s not found on %s", domain.ErrUnsupportedCurrency, target, date)
+		}
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         rateValue,
+			EffectiveDate: date,
+			Provider:      ProviderFrankfurter,
+		})
+	}
+
+	return rates, nil
+}
+
+// ListCurrencies returns the currencies Frankfurter quotes, from the /currencies endpoint
+func (f *FrankfurterFetcher) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, f.URL+"currencies")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch currencies: %w", err)
 	}
 
-	return domain.Rate{
-		Base:   base,
-		Target: target,
-		Value:  rateValue,
-		Date:   data.Date,
-	}, nil
+	var names map[string]string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
+	}
+	return currencyCodes(names)
 }
@@ -0,0 +1,103 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrankfurterFetchRate_FallbackToLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"amount":1,"base":"CAD","date":"2026-03-20","rates":{"JPY":110.5}}`))
+	}))
+	defer srv.Close()
+
+	f := NewFrankfurterFetcher(srv.URL+"/", srv.Client(), 0)
+	got, err := f.FetchRate(context.Background(), "2026-03-21", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Rate{EffectiveDate: "2026-03-20", RequestedDate: "2026-03-21", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.5"), Provider: ProviderFrankfurter}, got)
+}
+
+func TestFrankfurterFetchRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2026-03-13..2026-03-17", r.URL.Path)
+		assert.Equal(t, "CAD", r.URL.Query().Get("from"))
+		assert.Equal(t, "JPY", r.URL.Query().Get("to"))
+		_, _ = w.Write([]byte(`{"amount":1,"base":"CAD","start_date":"2026-03-13","end_date":"2026-03-17",` +
+			`"rates":{"2026-03-17":{"JPY":111.2},"2026-03-13":{"JPY":110.1},"2026-03-16":{"JPY":110.9}}}`))
+	}))
+	defer srv.Close()
+
+	f := NewFrankfurterFetcher(srv.URL+"/", srv.Client(), 0)
+	got, err := f.FetchRange(context.Background(), "2026-03-13", "2026-03-17", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Rate{
+		{EffectiveDate: "2026-03-13", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.1"), Provider: ProviderFrankfurter},
+		{EffectiveDate: "2026-03-16", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.9"), Provider: ProviderFrankfurter},
+		{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("111.2"), Provider: ProviderFrankfurter},
+	}, got)
+}
+
+func TestFrankfurterFetchRates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "JPY,USD", r.URL.Query().Get("to"))
+		_, _ = w.Write([]byte(`{"amount":1,"base":"CAD","date":"2026-03-19","rates":{"USD":0.74,"JPY":110.22}}`))
+	}))
+	defer srv.Close()
+
+	f := NewFrankfurterFetcher(srv.URL+"/", srv.Client(), 0)
+	got, err := f.FetchRates(context.Background(), "2026-03-19", "CAD", []domain.Currency{"JPY", "USD"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Rate{
+		{EffectiveDate: "2026-03-19", RequestedDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22"), Provider: ProviderFrankfurter},
+		{EffectiveDate: "2026-03-19", RequestedDate: "2026-03-19", Base: "CAD", Target: "USD", Value: domain.MustParseDecimal("0.74"), Provider: ProviderFrankfurter},
+	}, got)
+}
+
+func TestFrankfurterListCurrencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []domain.Currency
+		wantErr error
+	}{
+		{
+			name: "success",
+			body: `{"USD":"United States Dollar","JPY":"Japanese Yen","CAD":"Canadian Dollar"}`,
+			want: []domain.Currency{"CAD", "JPY", "USD"},
+		},
+		{name: "error: empty listing", body: `{}`, wantErr: domain.ErrMalformedResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/currencies", r.URL.Path)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			f := NewFrankfurterFetcher(srv.URL+"/", srv.Client(), 0)
+			got, err := f.ListCurrencies(context.Background())
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,31 +1,107 @@
 package rate
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+	"yenup/internal/infrastructure/httpclient"
 )
 
-// doGet performs an HTTP GET request and returns the response body
-func doGet(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+// doGet performs an HTTP GET request bound to ctx and returns the response body.
+// Failures are wrapped with the matching domain error so callers can use errors.Is.
+func doGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("not found: %s", resp.Status)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to fetch: %w", domain.ErrProviderUnavailable, err)
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", domain.ErrRateNotFound, resp.Status)
+	case resp.StatusCode == http.StatusUnprocessableEntity || resp.StatusCode == http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedCurrency, resp.Status)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w: %s", domain.ErrQuotaExceeded, resp.Status)
+	default:
+		return nil, fmt.Errorf("%w: HTTP error: %s", domain.ErrProviderUnavailable, resp.Status)
 	}
 
 	body, err := io.ReadAll(resp.Body)
+	if errors.Is(err, httpclient.ErrResponseTooLarge) {
+		return nil, fmt.Errorf("%w: %w", domain.ErrMalformedResponse, err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response body: %w", domain.ErrProviderUnavailable, err)
 	}
 
 	return body, nil
 }
+
+// withTimeout derives a per-call context from ctx.
+// A zero or negative timeout leaves ctx untouched so only the caller's deadline applies.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// sortedDates returns the YYYY-MM-DD keys of a time series response in ascending order
+func sortedDates[V any](series map[string]V) []string {
+	dates := make([]string, 0, len(series))
+	for date := range series {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// joinSymbols query-escapes each currency code and joins them with commas
+func joinSymbols(symbols []domain.Currency) string {
+	escaped := make([]string, len(symbols))
+	for i, s := range symbols {
+		escaped[i] = neturl.QueryEscape(string(s))
+	}
+	return strings.Join(escaped, ",")
+}
+
+// currencyCodes returns the ISO 4217 codes among the keys of a code -> name listing, sorted.
+// Codes outside ISO 4217 (such as crypto assets) are skipped. A listing without any
+// ISO 4217 code is malformed, since caching it would reject every currency.
+func currencyCodes(names map[string]string) ([]domain.Currency, error) {
+	codes := make([]string, 0, len(names))
+	for code := range names {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	currencies := make([]domain.Currency, 0, len(codes))
+	for _, code := range codes {
+		if c, err := domain.ParseCurrency(code); err == nil {
+			currencies = append(currencies, c)
+		}
+	}
+	if len(currencies) == 0 {
+		return nil, fmt.Errorf("%w: no currencies listed", domain.ErrMalformedResponse)
+	}
+	return currencies, nil
+}
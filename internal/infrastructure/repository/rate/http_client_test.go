@@ -0,0 +1,45 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "error: not found", status: http.StatusNotFound, wantErr: domain.ErrRateNotFound},
+		{name: "error: unprocessable currency", status: http.StatusUnprocessableEntity, wantErr: domain.ErrUnsupportedCurrency},
+		{name: "error: too many requests", status: http.StatusTooManyRequests, wantErr: domain.ErrQuotaExceeded},
+		{name: "error: server error", status: http.StatusInternalServerError, wantErr: domain.ErrProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			body, err := doGet(context.Background(), srv.Client(), srv.URL)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []byte(`{}`), body)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package rate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket limits outbound calls to Rate per second with bursts of up to Burst calls
+type TokenBucket struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket creates a new full TokenBucket
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		Rate:   rate,
+		Burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait blocks until a token is available or ctx is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise returns how long until the next one
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = min(float64(b.Burst), b.tokens+now.Sub(b.last).Seconds()*b.Rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.Rate * float64(time.Second))
+}
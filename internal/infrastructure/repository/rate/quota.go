@@ -0,0 +1,199 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"yenup/internal/domain/notifier"
+	domain "yenup/internal/domain/rate"
+	"yenup/internal/infrastructure/httpclient"
+)
+
+// QuotaFetcher meters calls to a single provider. Limiter paces outbound calls and
+// Usage counts them per calendar month (UTC). Once Limit calls were made in a month,
+// it fails with domain.ErrQuotaUsedUp without calling the provider, so a failover
+// chain moves on to the next one. Crossing WarnAt × Limit sends a warning through Notifier.
+type QuotaFetcher struct {
+	Name     string
+	Fetcher  domain.RateFetcher
+	Limiter  *TokenBucket      // nil means calls are not paced
+	Usage    domain.UsageStore // nil means calls are not counted
+	Limit    int               // monthly calls; 0 means no quota
+	WarnAt   float64           // fraction of Limit that triggers a warning, e.g. 0.8
+	Notifier notifier.Notifier
+	now      func() time.Time
+}
+
+// NewQuotaFetcher creates a new QuotaFetcher
+func NewQuotaFetcher(name string, fetcher domain.RateFetcher, limiter *TokenBucket, usage domain.UsageStore, limit int, warnAt float64, n notifier.Notifier) *QuotaFetcher {
+	return &QuotaFetcher{
+		Name:     name,
+		Fetcher:  fetcher,
+		Limiter:  limiter,
+		Usage:    usage,
+		Limit:    limit,
+		WarnAt:   warnAt,
+		Notifier: n,
+		now:      time.Now,
+	}
+}
+
+// FetchRate calls the provider unless its monthly quota is used up
+func (q *QuotaFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	var r domain.Rate
+	err := q.call(ctx, 1, func(ctx context.Context) error {
+		var err error
+		r, err = q.Fetcher.FetchRate(ctx, date, base, target)
+		return err
+	})
+	return r, err
+}
+
+// FetchRates calls the provider unless its monthly quota is used up.
+// Providers without batch support are queried, and metered, one target at a time.
+func (q *QuotaFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	if batch, ok := q.Fetcher.(domain.BatchFetcher); ok {
+		var rates []domain.Rate
+		err := q.call(ctx, 1, func(ctx context.Context) error {
+			var err error
+			rates, err = batch.FetchRates(ctx, date, base, targets)
+			return err
+		})
+		return rates, err
+	}
+
+	rates := make([]domain.Rate, 0, len(targets))
+	for _, target := range targets {
+		r, err := q.FetchRate(ctx, date, base, target)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+// FetchRange calls the provider unless its monthly quota is used up.
+// Providers without a series endpoint are queried, and metered, one day at a time.
+func (q *QuotaFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	ranger, ok := q.Fetcher.(domain.RangeFetcher)
+	if !ok {
+		return fetchDaily(ctx, q, from, to, base, target)
+	}
+
+	var rates []domain.Rate
+	err := q.call(ctx, 1, func(ctx context.Context) error {
+		var err error
+		rates, err = ranger.FetchRange(ctx, from, to, base, target)
+		return err
+	})
+	return rates, err
+}
+
+// ListCurrencies meters a currency list request like any other call to the provider.
+// It fails when the provider cannot list its currencies.
+func (q *QuotaFetcher) ListCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	lister, ok := q.Fetcher.(domain.CurrencyLister)
+	if !ok {
+		return nil, fmt.Errorf("rate provider %s cannot list its currencies", q.Name)
+	}
+
+	var currencies []domain.Currency
+	err := q.call(ctx, 1, func(ctx context.Context) error {
+		var err error
+		currencies, err = lister.ListCurrencies(ctx)
+		return err
+	})
+	return currencies, err
+}
+
+// call meters n calls and runs fetch. HTTP retries reach the provider as well,
+// so each one is metered before it is sent, and refused once the quota is used up.
+func (q *QuotaFetcher) call(ctx context.Context, n int, fetch func(ctx context.Context) error) error {
+	if err := q.meter(ctx, n); err != nil {
+		return err
+	}
+	return fetch(httpclient.WithRetryHook(ctx, func() error {
+		return q.meter(ctx, 1)
+	}))
+}
+
+// meter reserves n calls in this month's quota, then waits for the limiter.
+// The provider counts every request it received, so calls are recorded whether they succeed or not.
+func (q *QuotaFetcher) meter(ctx context.Context, n int) error {
+	month := q.now().UTC().Format("2006-01")
+
+	if q.Usage != nil {
+		used, err := q.Usage.Reserve(ctx, q.Name, month, n, q.Limit)
+		switch {
+		case errors.Is(err, domain.ErrQuotaExceeded):
+			return err
+		case err != nil:
+			// an unusable counter should not take the provider down with it
+			log.Printf("failed to record %s usage: %v", q.Name, err)
+		default:
+			q.warn(used-n, used, month)
+		}
+	}
+
+	if q.Limiter != nil {
+		if err := q.Limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait for %s: %w", q.Name, err)
+		}
+	}
+	return nil
+}
+
+// warn notifies once when usage crosses the warning threshold and once when it reaches the limit
+func (q *QuotaFetcher) warn(before, after int, month string) {
+	if q.Limit <= 0 || q.Notifier == nil {
+		return
+	}
+
+	var message string
+	switch threshold := int(math.Ceil(float64(q.Limit) * q.WarnAt)); {
+	case before < q.Limit && after >= q.Limit:
+		message = fmt.Sprintf("⛔ Rate provider %s reached its monthly quota (%d of %d calls in %s). It will not be called until next month.",
+			q.Name, after, q.Limit, month)
+	case q.WarnAt > 0 && before < threshold && after >= threshold:
+		message = fmt.Sprintf("⚠️ Rate provider %s has used %d of %d monthly calls in %s.",
+			q.Name, after, q.Limit, month)
+	default:
+		return
+	}
+
+	if err := q.Notifier.Notify(message); err != nil {
+		log.Printf("failed to send quota warning for %s: %v", q.Name, err)
+	}
+}
+
+// MemoryUsageStore is an in-process domain.UsageStore; counts are lost on restart
+type MemoryUsageStore struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewMemoryUsageStore creates a new empty MemoryUsageStore
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{
+		counts: make(map[string]int),
+	}
+}
+
+// Reserve records n more calls unless that would take the total past limit
+func (m *MemoryUsageStore) Reserve(ctx context.Context, provider, month string, n, limit int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := provider + "|" + month
+	if used := m.counts[key]; limit > 0 && used+n > limit {
+		return used, fmt.Errorf("%w: %s used %d of %d calls in %s", domain.ErrQuotaUsedUp, provider, used, limit, month)
+	}
+	m.counts[key] += n
+	return m.counts[key], nil
+}
@@ -0,0 +1,134 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+	"yenup/internal/infrastructure/httpclient"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Notify(message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestQuotaFetcher(t *testing.T) {
+	tests := []struct {
+		name         string
+		used         int
+		calls        int
+		wantErr      error
+		wantUpstream int
+		wantNotices  int
+	}{
+		{name: "success: below the warning threshold", used: 0, calls: 3, wantUpstream: 3},
+		{name: "success: crossing the threshold warns once", used: 6, calls: 3, wantUpstream: 3, wantNotices: 1},
+		{name: "error: limit reached stops calling the provider", used: 9, calls: 3, wantErr: domain.ErrQuotaUsedUp, wantUpstream: 1, wantNotices: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := &stubFetcher{rate: domain.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.22")}}
+			usage := NewMemoryUsageStore()
+			_, _ = usage.Reserve(context.Background(), "exchangerates", "2026-03", tt.used, 0)
+			notices := &recordingNotifier{}
+
+			q := NewQuotaFetcher("exchangerates", upstream, nil, usage, 10, 0.8, notices)
+			q.now = func() time.Time { return time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC) }
+
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				_, err = q.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantUpstream, upstream.calls)
+			assert.Len(t, notices.messages, tt.wantNotices)
+		})
+	}
+}
+
+func TestQuotaFetcher_MetersRetriesAndCurrencyLists(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/currencies" {
+			_, _ = w.Write([]byte(`{"CAD":"Canadian Dollar","JPY":"Japanese Yen"}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: httpclient.NewRetryTransport(nil, httpclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})}
+	usage := NewMemoryUsageStore()
+	q := NewQuotaFetcher("frankfurter", NewFrankfurterFetcher(srv.URL+"/", client, 0), nil, usage, 4, 0, nil)
+	q.now = func() time.Time { return time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC) }
+
+	_, err := q.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+	assert.ErrorIs(t, err, domain.ErrProviderUnavailable)
+	_, err = q.ListCurrencies(context.Background())
+	assert.NoError(t, err)
+	_, err = q.FetchRate(context.Background(), "2026-03-19", "CAD", "JPY")
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+
+	// three attempts for the first rate and one for the list; the quota then refuses the next call
+	assert.Equal(t, 4, calls)
+	used, err := usage.Reserve(context.Background(), "frankfurter", "2026-03", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, used)
+}
+
+func TestMemoryUsageStore_ConcurrentReserve(t *testing.T) {
+	usage := NewMemoryUsageStore()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	granted := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := usage.Reserve(context.Background(), "exchangerates", "2026-03", 1, 10); err == nil {
+				mu.Lock()
+				granted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, granted)
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(10, 2)
+	clock := time.Date(2026, 3, 19, 9, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return clock }
+
+	assert.Zero(t, b.reserve())
+	assert.Zero(t, b.reserve())
+	assert.Equal(t, 100*time.Millisecond, b.reserve())
+
+	clock = clock.Add(100 * time.Millisecond)
+	assert.Zero(t, b.reserve())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.Wait(ctx), context.Canceled)
+}
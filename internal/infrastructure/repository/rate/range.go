@@ -0,0 +1,51 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// fetchRange asks fetcher for the base/target series between from and to in one call,
+// or fetches it day by day when fetcher has no series endpoint
+func fetchRange(ctx context.Context, fetcher domain.RateFetcher, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	if ranger, ok := fetcher.(domain.RangeFetcher); ok {
+		return ranger.FetchRange(ctx, from, to, base, target)
+	}
+	return fetchDaily(ctx, fetcher, from, to, base, target)
+}
+
+// fetchDaily fetches every day between from and to separately. Days without a publication
+// are answered with an earlier effective date, so each effective date is kept once and
+// dates before from are dropped.
+func fetchDaily(ctx context.Context, fetcher domain.RateFetcher, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range start: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range end: %w", err)
+	}
+
+	var rates []domain.Rate
+	seen := make(map[string]bool)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		r, err := fetcher.FetchRate(ctx, day.Format("2006-01-02"), base, target)
+		if err != nil {
+			return nil, err
+		}
+		if r.EffectiveDate == "" {
+			r.EffectiveDate = day.Format("2006-01-02")
+		}
+		if r.EffectiveDate < from || seen[r.EffectiveDate] {
+			continue
+		}
+		seen[r.EffectiveDate] = true
+		r.RequestedDate = ""
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
@@ -0,0 +1,102 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// businessDayFetcher answers weekends with the preceding Friday, like the providers do
+type businessDayFetcher struct {
+	calls int
+}
+
+func (b *businessDayFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	b.calls++
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return domain.Rate{
+		EffectiveDate: day.Format("2006-01-02"),
+		RequestedDate: date,
+		Base:          base,
+		Target:        target,
+		Value:         domain.NewDecimalFromInt(int64(100 + day.Day())),
+	}, nil
+}
+
+// seriesFetcher serves whole series per pair and quotes no single days
+type seriesFetcher struct {
+	series map[string][]domain.Rate
+}
+
+func (s *seriesFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	return domain.Rate{}, fmt.Errorf("%w: single days are not served", domain.ErrUnsupportedCurrency)
+}
+
+func (s *seriesFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	rates, ok := s.series[string(base)+"/"+string(target)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", domain.ErrUnsupportedCurrency, base, target)
+	}
+	return append([]domain.Rate(nil), rates...), nil
+}
+
+func TestFetchDaily(t *testing.T) {
+	fetcher := &businessDayFetcher{}
+
+	// Sunday 2026-03-15 to Tuesday 2026-03-17: the Sunday answer is Friday's, before the range
+	rates, err := fetchRange(context.Background(), fetcher, "2026-03-13", "2026-03-17", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, fetcher.calls)
+	var dates []string
+	for _, r := range rates {
+		dates = append(dates, r.EffectiveDate)
+		assert.Empty(t, r.RequestedDate)
+	}
+	assert.Equal(t, []string{"2026-03-13", "2026-03-16", "2026-03-17"}, dates)
+}
+
+func TestTriangulatingFetcher_FetchRange(t *testing.T) {
+	fetcher := &seriesFetcher{series: map[string][]domain.Rate{
+		"USD/CAD": {
+			{EffectiveDate: "2026-03-18", Base: "USD", Target: "CAD", Value: domain.MustParseDecimal("1.25")},
+			{EffectiveDate: "2026-03-19", Base: "USD", Target: "CAD", Value: domain.MustParseDecimal("1.5")},
+		},
+		"USD/JPY": {
+			{EffectiveDate: "2026-03-17", Base: "USD", Target: "JPY", Value: domain.MustParseDecimal("149")},
+			{EffectiveDate: "2026-03-18", Base: "USD", Target: "JPY", Value: domain.MustParseDecimal("150")},
+			{EffectiveDate: "2026-03-19", Base: "USD", Target: "JPY", Value: domain.MustParseDecimal("153")},
+		},
+	}}
+	tf := NewTriangulatingFetcher(fetcher, domain.Precision{Scale: 4, Mode: domain.RoundHalfEven}, "USD")
+
+	rates, err := tf.FetchRange(context.Background(), "2026-03-17", "2026-03-19", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	if assert.Len(t, rates, 2) {
+		// 2026-03-17 is missing from the USD/CAD leg
+		assert.Equal(t, "2026-03-18", rates[0].EffectiveDate)
+		assert.Equal(t, "120", rates[0].Value.String())
+		assert.Equal(t, "2026-03-19", rates[1].EffectiveDate)
+		assert.Equal(t, "102", rates[1].Value.String())
+		assert.Equal(t, domain.Currency("USD"), rates[1].Pivot)
+		assert.True(t, rates[1].Derived)
+	}
+
+	inverted, err := tf.FetchRange(context.Background(), "2026-03-17", "2026-03-19", "JPY", "USD")
+
+	assert.NoError(t, err)
+	assert.Len(t, inverted, 3)
+	assert.Equal(t, domain.Pair{Base: "JPY", Target: "USD"}, inverted[0].Pair())
+}
@@ -0,0 +1,217 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "yenup/internal/domain/rate"
+)
+
+var one = domain.NewDecimalFromInt(1)
+
+// TriangulatingFetcher derives pairs the wrapped fetcher does not quote directly.
+// It first asks for base/target, then inverts target/base, and finally crosses two legs
+// through each pivot in order: base/target = (pivot/target) ÷ (pivot/base).
+// Each leg may itself be quoted directly or inverted.
+type TriangulatingFetcher struct {
+	Fetcher   domain.RateFetcher
+	Pivots    []domain.Currency
+	Precision domain.Precision
+}
+
+// NewTriangulatingFetcher creates a new TriangulatingFetcher
+func NewTriangulatingFetcher(fetcher domain.RateFetcher, precision domain.Precision, pivots ...domain.Currency) *TriangulatingFetcher {
+	return &TriangulatingFetcher{
+		Fetcher:   fetcher,
+		Pivots:    pivots,
+		Precision: precision,
+	}
+}
+
+// FetchRate returns base/target, derived when the provider does not quote it.
+// Derived rates have Derived set, and Pivot names the intermediate currency if one was used.
+func (f *TriangulatingFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	r, err := f.leg(ctx, date, base, target)
+	if err == nil || !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		return r, err
+	}
+
+	errs := []error{err}
+	for _, pivot := range f.Pivots {
+		if pivot == base || pivot == target {
+			continue
+		}
+		r, err := f.viaPivot(ctx, date, base, target, pivot)
+		if err == nil {
+			return r, nil
+		}
+		errs = append(errs, fmt.Errorf("via %s: %w", pivot, err))
+		if !errors.Is(err, domain.ErrUnsupportedCurrency) {
+			// provider trouble is not something another pivot can fix
+			break
+		}
+	}
+	return domain.Rate{}, errors.Join(errs...)
+}
+
+// FetchRates uses the wrapped fetcher's batch call when every target is quoted directly,
+// and derives the targets one by one otherwise
+func (f *TriangulatingFetcher) FetchRates(ctx context.Context, date string, base domain.Currency, targets []domain.Currency) ([]domain.Rate, error) {
+	if batch, ok := f.Fetcher.(domain.BatchFetcher); ok {
+		rates, err := batch.FetchRates(ctx, date, base, targets)
+		if err == nil || !errors.Is(err, domain.ErrUnsupportedCurrency) {
+			return rates, err
+		}
+	}
+
+	rates := make([]domain.Rate, 0, len(targets))
+	for _, target := range targets {
+		r, err := f.FetchRate(ctx, date, base, target)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+// FetchRange returns the base/target series, derived like FetchRate when the provider
+// does not quote the pair. A cross only covers the dates both legs were published on.
+func (f *TriangulatingFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	rates, err := f.rangeLeg(ctx, from, to, base, target)
+	if err == nil || !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		return rates, err
+	}
+
+	errs := []error{err}
+	for _, pivot := range f.Pivots {
+		if pivot == base || pivot == target {
+			continue
+		}
+		rates, err := f.rangeViaPivot(ctx, from, to, base, target, pivot)
+		if err == nil {
+			return rates, nil
+		}
+		errs = append(errs, fmt.Errorf("via %s: %w", pivot, err))
+		if !errors.Is(err, domain.ErrUnsupportedCurrency) {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// leg fetches base/target directly, or inverts target/base when the pair is not quoted
+func (f *TriangulatingFetcher) leg(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	r, err := f.Fetcher.FetchRate(ctx, date, base, target)
+	if err == nil || !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		return r, err
+	}
+
+	inverse, invErr := f.Fetcher.FetchRate(ctx, date, target, base)
+	if invErr != nil {
+		return domain.Rate{}, errors.Join(err, invErr)
+	}
+	return f.invert(inverse)
+}
+
+// invert turns a target/base rate into base/target
+func (f *TriangulatingFetcher) invert(inverse domain.Rate) (domain.Rate, error) {
+	value, err := one.Div(inverse.Value, f.Precision)
+	if err != nil {
+		return domain.Rate{}, fmt.Errorf("failed to invert %s/%s: %w", inverse.Base, inverse.Target, err)
+	}
+
+	inverse.Base, inverse.Target = inverse.Target, inverse.Base
+	inverse.Value = value
+	inverse.Derived = true
+	return inverse, nil
+}
+
+// viaPivot crosses pivot/base and pivot/target
+func (f *TriangulatingFetcher) viaPivot(ctx context.Context, date string, base, target, pivot domain.Currency) (domain.Rate, error) {
+	pivotBase, err := f.leg(ctx, date, pivot, base)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	pivotTarget, err := f.leg(ctx, date, pivot, target)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+
+	value, err := pivotTarget.Value.Div(pivotBase.Value, f.Precision)
+	if err != nil {
+		return domain.Rate{}, fmt.Errorf("failed to cross via %s: %w", pivot, err)
+	}
+
+	r := pivotTarget
+	r.Base, r.Target = base, target
+	r.Value = value
+	r.Derived = true
+	r.Pivot = pivot
+	r.RequestedDate = date
+	// the cross is only as recent as its older leg
+	if pivotBase.EffectiveDate < r.EffectiveDate {
+		r.EffectiveDate = pivotBase.EffectiveDate
+	}
+	return r, nil
+}
+
+// rangeLeg fetches the base/target series directly, or inverts the target/base series
+func (f *TriangulatingFetcher) rangeLeg(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	rates, err := fetchRange(ctx, f.Fetcher, from, to, base, target)
+	if err == nil || !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		return rates, err
+	}
+
+	inverses, invErr := fetchRange(ctx, f.Fetcher, from, to, target, base)
+	if invErr != nil {
+		return nil, errors.Join(err, invErr)
+	}
+	rates = make([]domain.Rate, 0, len(inverses))
+	for _, inverse := range inverses {
+		r, err := f.invert(inverse)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
+
+// rangeViaPivot crosses the pivot/base and pivot/target series date by date
+func (f *TriangulatingFetcher) rangeViaPivot(ctx context.Context, from, to string, base, target, pivot domain.Currency) ([]domain.Rate, error) {
+	pivotBases, err := f.rangeLeg(ctx, from, to, pivot, base)
+	if err != nil {
+		return nil, err
+	}
+	pivotTargets, err := f.rangeLeg(ctx, from, to, pivot, target)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]domain.Rate, len(pivotBases))
+	for _, r := range pivotBases {
+		byDate[r.EffectiveDate] = r
+	}
+
+	rates := make([]domain.Rate, 0, len(pivotTargets))
+	for _, pivotTarget := range pivotTargets {
+		pivotBase, ok := byDate[pivotTarget.EffectiveDate]
+		if !ok {
+			continue
+		}
+		value, err := pivotTarget.Value.Div(pivotBase.Value, f.Precision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cross via %s on %s: %w", pivot, pivotTarget.EffectiveDate, err)
+		}
+
+		r := pivotTarget
+		r.Base, r.Target = base, target
+		r.Value = value
+		r.Derived = true
+		r.Pivot = pivot
+		rates = append(rates, r)
+	}
+	return rates, nil
+}
@@ -0,0 +1,110 @@
+package rate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pairFetcher quotes only the pairs in its table
+type pairFetcher struct {
+	quotes map[string]domain.Rate
+	err    error
+}
+
+func (p *pairFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	if p.err != nil {
+		return domain.Rate{}, p.err
+	}
+	r, ok := p.quotes[string(base)+"/"+string(target)]
+	if !ok {
+		return domain.Rate{}, fmt.Errorf("%w: %s/%s", domain.ErrUnsupportedCurrency, base, target)
+	}
+	return r, nil
+}
+
+func TestTriangulatingFetcher(t *testing.T) {
+	// a provider that only lists USD-based quotes
+	usdQuotes := map[string]domain.Rate{
+		"USD/CAD": {EffectiveDate: "2026-03-19", Base: "USD", Target: "CAD", Value: domain.MustParseDecimal("1.25")},
+		"USD/JPY": {EffectiveDate: "2026-03-20", Base: "USD", Target: "JPY", Value: domain.MustParseDecimal("150")},
+	}
+
+	tests := []struct {
+		name        string
+		fetcher     *pairFetcher
+		base        domain.Currency
+		target      domain.Currency
+		wantValue   string
+		wantDate    string
+		wantDerived bool
+		wantPivot   domain.Currency
+		wantErr     error
+	}{
+		{
+			name:      "success: direct quote is passed through",
+			fetcher:   &pairFetcher{quotes: usdQuotes},
+			base:      "USD",
+			target:    "JPY",
+			wantValue: "150",
+			wantDate:  "2026-03-20",
+		},
+		{
+			name:        "success: inverted pair",
+			fetcher:     &pairFetcher{quotes: usdQuotes},
+			base:        "CAD",
+			target:      "USD",
+			wantValue:   "0.8",
+			wantDate:    "2026-03-19",
+			wantDerived: true,
+		},
+		{
+			name:        "success: crossed through USD, dated by the older leg",
+			fetcher:     &pairFetcher{quotes: usdQuotes},
+			base:        "CAD",
+			target:      "JPY",
+			wantValue:   "120",
+			wantDate:    "2026-03-19",
+			wantDerived: true,
+			wantPivot:   "USD",
+		},
+		{
+			name:    "error: no pivot connects the pair",
+			fetcher: &pairFetcher{quotes: usdQuotes},
+			base:    "CAD",
+			target:  "AUD",
+			wantErr: domain.ErrUnsupportedCurrency,
+		},
+		{
+			name:    "error: provider failure is not triangulated",
+			fetcher: &pairFetcher{err: domain.ErrProviderUnavailable},
+			base:    "CAD",
+			target:  "JPY",
+			wantErr: domain.ErrProviderUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewTriangulatingFetcher(tt.fetcher, domain.DefaultPrecision, "EUR", "USD")
+
+			got, err := f.FetchRate(context.Background(), "2026-03-20", tt.base, tt.target)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.base, got.Base)
+			assert.Equal(t, tt.target, got.Target)
+			assert.Equal(t, tt.wantValue, got.Value.String())
+			assert.Equal(t, tt.wantDate, got.EffectiveDate)
+			assert.Equal(t, tt.wantDerived, got.Derived)
+			assert.Equal(t, tt.wantPivot, got.Pivot)
+		})
+	}
+}
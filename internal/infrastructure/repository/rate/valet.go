@@ -0,0 +1,153 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	domain "yenup/internal/domain/rate"
+)
+
+// ProviderBankOfCanada is the provider name recorded in rates fetched from the Bank of Canada Valet API
+const ProviderBankOfCanada = "bankofcanada"
+
+// valetLookback is how far back FetchRate searches for the last published observation.
+// It covers weekends plus the longest run of Canadian bank holidays.
+const valetLookback = 7 * 24 * time.Hour
+
+// ValetResponse is the response structure for the Valet observations endpoint.
+// Each observation holds the date under "d" and one {"v": "..."} object per series.
+type ValetResponse struct {
+	Observations []map[string]json.RawMessage `json:"observations"`
+}
+
+// ValetValue is the value of one series in an observation, published as a string
+type ValetValue struct {
+	V string `json:"v"`
+}
+
+// ValetFetcher fetches Bank of Canada daily exchange rates from the Valet API.
+// The Bank only publishes pairs against CAD, one series per pair such as FXCADJPY.
+type ValetFetcher struct {
+	URL     string
+	Client  *http.Client
+	Timeout time.Duration // per HTTP call; zero means no extra deadline beyond ctx
+}
+
+// NewValetFetcher creates a new ValetFetcher
+func NewValetFetcher(url string, client *http.Client, timeout time.Duration) *ValetFetcher {
+	return &ValetFetcher{
+		URL:     url,
+		Client:  client,
+		Timeout: timeout,
+	}
+}
+
+// FetchRate fetches base/target for date.
+// The Bank does not publish on weekends and Canadian holidays, so the last observation
+// on or before date is returned.
+func (f *ValetFetcher) FetchRate(ctx context.Context, date string, base, target domain.Currency) (domain.Rate, error) {
+	query := neturl.Values{}
+	if date == "latest" {
+		query.Set("recent", "1")
+	} else {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return domain.Rate{}, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		query.Set("start_date", day.Add(-valetLookback).Format(time.DateOnly))
+		query.Set("end_date", date)
+	}
+
+	rates, err := f.fetchObservations(ctx, query, base, target)
+	if err != nil {
+		return domain.Rate{}, fmt.Errorf("failed to fetch rate: %w", err)
+	}
+	if len(rates) == 0 {
+		return domain.Rate{}, fmt.Errorf("%w: no %s/%s observation on or before %s", domain.ErrRateNotFound, base, target, date)
+	}
+	r := rates[len(rates)-1]
+	r.RequestedDate = date
+	return r, nil
+}
+
+// FetchRange fetches daily rates between from and to using the start_date/end_date query.
+// Non-publishing days are absent from the result.
+func (f *ValetFetcher) FetchRange(ctx context.Context, from, to string, base, target domain.Currency) ([]domain.Rate, error) {
+	query := neturl.Values{}
+	query.Set("start_date", from)
+	query.Set("end_date", to)
+
+	rates, err := f.fetchObservations(ctx, query, base, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rate range: %w", err)
+	}
+	return rates, nil
+}
+
+// fetchObservations queries the base/target series and returns its published observations in date order
+func (f *ValetFetcher) fetchObservations(ctx context.Context, query neturl.Values, base, target domain.Currency) ([]domain.Rate, error) {
+	if base != "CAD" && target != "CAD" {
+		return nil, fmt.Errorf("%w: Bank of Canada only publishes rates against CAD", domain.ErrUnsupportedCurrency)
+	}
+	series := "FX" + string(base) + string(target)
+	url := fmt.Sprintf("%sobservations/%s/json?%s", f.URL, neturl.PathEscape(series), query.Encode())
+
+	ctx, cancel := withTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	body, err := doGet(ctx, f.Client, url)
+	if err != nil {
+		// Valet answers 404 for series it does not publish; the triangulating fetcher can still invert the opposite pair
+		if errors.Is(err, domain.ErrRateNotFound) {
+			return nil, fmt.Errorf("%w: series %s not published", domain.ErrUnsupportedCurrency, series)
+		}
+		return nil, err
+	}
+
+	var data ValetResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse JSON: %w", domain.ErrMalformedResponse, err)
+	}
+
+	byDate := make(map[string]domain.Decimal, len(data.Observations))
+	for _, obs := range data.Observations {
+		var date string
+		if err := json.Unmarshal(obs["d"], &date); err != nil {
+			return nil, fmt.Errorf("%w: observation date: %w", domain.ErrMalformedResponse, err)
+		}
+		raw, ok := obs[series]
+		if !ok {
+			continue
+		}
+		var value ValetValue
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("%w: %s on %s: %w", domain.ErrMalformedResponse, series, date, err)
+		}
+		// blank values mark days the Bank did not publish
+		if value.V == "" {
+			continue
+		}
+		rateValue, err := domain.ParseDecimal(value.V)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s on %s: %w", domain.ErrMalformedResponse, series, date, err)
+		}
+		byDate[date] = rateValue
+	}
+
+	rates := make([]domain.Rate, 0, len(byDate))
+	for _, date := range sortedDates(byDate) {
+		rates = append(rates, domain.Rate{
+			Base:          base,
+			Target:        target,
+			Value:         byDate[date],
+			EffectiveDate: date,
+			Provider:      ProviderBankOfCanada,
+		})
+	}
+	return rates, nil
+}
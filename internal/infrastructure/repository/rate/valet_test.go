@@ -0,0 +1,91 @@
+package rate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domain "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newValetServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/observations/FXCADJPY/json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValetFetchRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		target   domain.Currency
+		wantRate domain.Rate
+		wantErr  error
+	}{
+		{
+			name: "success: last published observation before a holiday",
+			body: `{"observations":[{"d":"2026-03-19","FXCADJPY":{"v":"110.22"}},` +
+				`{"d":"2026-03-20","FXCADJPY":{"v":"110.50"}},{"d":"2026-03-23","FXCADJPY":{"v":""}}]}`,
+			target:   "JPY",
+			wantRate: domain.Rate{EffectiveDate: "2026-03-20", RequestedDate: "2026-03-23", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.5"), Provider: ProviderBankOfCanada},
+		},
+		{
+			name:    "error: nothing published in the lookback window",
+			body:    `{"observations":[]}`,
+			target:  "JPY",
+			wantErr: domain.ErrRateNotFound,
+		},
+		{
+			name:    "error: series not published",
+			target:  "XAU",
+			wantErr: domain.ErrUnsupportedCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewValetFetcher(newValetServer(t, tt.body).URL+"/", nil, 0)
+
+			got, err := f.FetchRate(context.Background(), "2026-03-23", "CAD", tt.target)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRate, got)
+		})
+	}
+}
+
+func TestValetFetchRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/observations/FXCADJPY/json", r.URL.Path)
+		assert.Equal(t, "2026-03-13", r.URL.Query().Get("start_date"))
+		assert.Equal(t, "2026-03-17", r.URL.Query().Get("end_date"))
+		_, _ = w.Write([]byte(`{"seriesDetail":{"FXCADJPY":{"label":"CAD/JPY"}},"observations":[` +
+			`{"d":"2026-03-13","FXCADJPY":{"v":"110.1"}},{"d":"2026-03-16","FXCADJPY":{"v":"110.9"}},` +
+			`{"d":"2026-03-17","FXCADJPY":{"v":"111.2"}}]}`))
+	}))
+	defer srv.Close()
+
+	f := NewValetFetcher(srv.URL+"/", srv.Client(), 0)
+	got, err := f.FetchRange(context.Background(), "2026-03-13", "2026-03-17", "CAD", "JPY")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Rate{
+		{EffectiveDate: "2026-03-13", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.1"), Provider: ProviderBankOfCanada},
+		{EffectiveDate: "2026-03-16", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("110.9"), Provider: ProviderBankOfCanada},
+		{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: domain.MustParseDecimal("111.2"), Provider: ProviderBankOfCanada},
+	}, got)
+}
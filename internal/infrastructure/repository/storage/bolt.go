@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
+)
+
+// historyBucket is the top-level bucket; it holds one nested bucket per pair ("CAD/JPY")
+// whose keys are effective dates (YYYY-MM-DD) and values are JSON-encoded rates
+var historyBucket = []byte("history")
+
+// BoltClient keeps the rate history in an embedded single-file B+tree database (bbolt),
+// for single-VM deployments that keep long histories without any cloud service.
+// Dates sort lexicographically, so range queries are cursor scans, and a write only
+// touches the dates that changed. The version of a pair is the sequence of its bucket,
+// bumped on every write.
+type BoltClient struct {
+	db *bolt.DB
+}
+
+// NewBoltClient opens (or creates) the database file at path. The file is locked
+// by this process until Close; a second process waits up to timeout for the lock.
+func NewBoltClient(path string, timeout time.Duration) (*BoltClient, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(historyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	return &BoltClient{db: db}, nil
+}
+
+// Close releases the database file.
+func (b *BoltClient) Close() error {
+	return b.db.Close()
+}
+
+// Read returns the whole history of pair in date order.
+func (b *BoltClient) Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, domainStorage.Version, error) {
+	rates := []*rate.Rate{}
+	var version domainStorage.Version
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(pair.String()))
+		if bucket == nil {
+			return nil
+		}
+		version = domainStorage.Version(bucket.Sequence())
+		return bucket.ForEach(func(_, value []byte) error {
+			r, err := decodeRate(value)
+			if err != nil {
+				return err
+			}
+			rates = append(rates, r)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read history of %s: %w", pair, err)
+	}
+	return rates, version, nil
+}
+
+// ReadRange returns the rates of pair effective between from and to, in date order.
+func (b *BoltClient) ReadRange(ctx context.Context, pair rate.Pair, from, to string) ([]*rate.Rate, error) {
+	rates := []*rate.Rate{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(pair.String()))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for key, value := c.Seek([]byte(from)); key != nil && bytes.Compare(key, []byte(to)) <= 0; key, value = c.Next() {
+			r, err := decodeRate(value)
+			if err != nil {
+				return err
+			}
+			rates = append(rates, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", pair, err)
+	}
+	return rates, nil
+}
+
+// Write replaces the history of pair, storing only the dates that changed. It fails with
+// a *domainStorage.ConflictError when the pair was written since version was read.
+func (b *BoltClient) Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version domainStorage.Version) error {
+	values := make(map[string][]byte, len(rates))
+	for _, r := range rates {
+		value, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rate: %w", err)
+		}
+		values[r.EffectiveDate] = value
+	}
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(pair.String()))
+		if err != nil {
+			return err
+		}
+		if domainStorage.Version(bucket.Sequence()) != version {
+			return &domainStorage.ConflictError{Pair: pair, Version: version}
+		}
+
+		// drop dates that are no longer in the history; deleting while iterating
+		// is not supported by bbolt cursors, so collect them first
+		var stale [][]byte
+		err = bucket.ForEach(func(key, _ []byte) error {
+			if _, ok := values[string(key)]; !ok {
+				stale = append(stale, bytes.Clone(key))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range stale {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		for date, value := range values {
+			if bytes.Equal(bucket.Get([]byte(date)), value) {
+				continue
+			}
+			if err := bucket.Put([]byte(date), value); err != nil {
+				return err
+			}
+		}
+		_, err = bucket.NextSequence()
+		return err
+	})
+	if err != nil {
+		var conflict *domainStorage.ConflictError
+		if errors.As(err, &conflict) {
+			return err
+		}
+		return fmt.Errorf("failed to write history of %s: %w", pair, err)
+	}
+	return nil
+}
+
+// PutRates stores each rate under its effective date in the bucket of its pair, in one
+// transaction and without touching other dates, then trims each pair to its latest limit dates.
+// Every pair written has its version bumped, so a concurrent Write of it conflicts.
+func (b *BoltClient) PutRates(ctx context.Context, rates []*rate.Rate, limit int) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		buckets := make(map[rate.Pair]*bolt.Bucket)
+		for _, r := range rates {
+			value, err := json.Marshal(r)
+			if err != nil {
+				return fmt.Errorf("failed to marshal rate: %w", err)
+			}
+			bucket, ok := buckets[r.Pair()]
+			if !ok {
+				if bucket, err = tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(r.Pair().String())); err != nil {
+					return err
+				}
+				buckets[r.Pair()] = bucket
+			}
+			if err := bucket.Put([]byte(r.EffectiveDate), value); err != nil {
+				return err
+			}
+		}
+
+		for _, bucket := range buckets {
+			if err := trimBucket(bucket, limit); err != nil {
+				return err
+			}
+			if _, err := bucket.NextSequence(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put rates: %w", err)
+	}
+	return nil
+}
+
+// trimBucket deletes all but the latest limit dates of bucket; 0 keeps all. Only the kept
+// dates and the deleted ones are visited, so trimming a long history by a day is cheap.
+func trimBucket(bucket *bolt.Bucket, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	c := bucket.Cursor()
+	oldestKept, _ := c.Last()
+	for i := 1; i < limit && oldestKept != nil; i++ {
+		oldestKept, _ = c.Prev()
+	}
+	if oldestKept == nil {
+		return nil
+	}
+
+	// deleting while iterating is not supported by bbolt cursors, so collect the keys first
+	var stale [][]byte
+	for key, _ := c.First(); key != nil && bytes.Compare(key, oldestKept) < 0; key, _ = c.Next() {
+		stale = append(stale, bytes.Clone(key))
+	}
+	for _, key := range stale {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pairs lists the pairs that have a history, sorted by name.
+func (b *BoltClient) Pairs(ctx context.Context) ([]rate.Pair, error) {
+	var pairs []rate.Pair
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyBucket).ForEachBucket(func(key []byte) error {
+			pair, err := rate.ParsePair(string(key))
+			if err != nil {
+				return fmt.Errorf("invalid pair in rate history: %w", err)
+			}
+			pairs = append(pairs, pair)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+func decodeRate(value []byte) (*rate.Rate, error) {
+	var r rate.Rate
+	if err := json.Unmarshal(value, &r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rate: %w", err)
+	}
+	return &r, nil
+}
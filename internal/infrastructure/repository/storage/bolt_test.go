@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cadRate(date, value string) *rate.Rate {
+	return &rate.Rate{EffectiveDate: date, Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal(value)}
+}
+
+func TestBoltClient_ReadWrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "rates.db")
+	client, err := NewBoltClient(path, time.Second)
+	assert.NoError(t, err)
+
+	rates, version, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Empty(t, rates)
+	assert.Equal(t, domainStorage.Version(0), version)
+
+	// stored out of order, read back in date order
+	history := []*rate.Rate{cadRate("2026-03-19", "110.22"), cadRate("2026-03-18", "112.5"), cadRate("2026-03-17", "111.8")}
+	assert.NoError(t, client.Write(ctx, cadJPY, history, version))
+
+	// a later write drops dates that are no longer part of the history
+	rates, version, err = client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Equal(t, []*rate.Rate{history[2], history[1], history[0]}, rates)
+	assert.NoError(t, client.Write(ctx, cadJPY, rates[1:], version))
+	assert.NoError(t, client.Close())
+
+	// the history survives reopening the file
+	client, err = NewBoltClient(path, time.Second)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	rates, _, err = client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Equal(t, []*rate.Rate{history[1], history[0]}, rates)
+	pairs, err := client.Pairs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []rate.Pair{cadJPY}, pairs)
+}
+
+func TestBoltClient_ReadRange(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewBoltClient(filepath.Join(t.TempDir(), "rates.db"), time.Second)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	var history []*rate.Rate
+	for _, date := range []string{"2025-12-31", "2026-01-02", "2026-01-05", "2026-01-06", "2026-02-01"} {
+		history = append(history, cadRate(date, "110"))
+	}
+	assert.NoError(t, client.Write(ctx, cadJPY, history, 0))
+	assert.NoError(t, client.Write(ctx, usdJPY, []*rate.Rate{{EffectiveDate: "2026-01-05", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157")}}, 0))
+
+	tests := []struct {
+		name      string
+		pair      rate.Pair
+		from, to  string
+		wantDates []string
+	}{
+		{name: "bounds are inclusive", pair: cadJPY, from: "2026-01-02", to: "2026-01-06", wantDates: []string{"2026-01-02", "2026-01-05", "2026-01-06"}},
+		{name: "bounds between stored dates", pair: cadJPY, from: "2026-01-01", to: "2026-01-31", wantDates: []string{"2026-01-02", "2026-01-05", "2026-01-06"}},
+		{name: "other pair is separate", pair: usdJPY, from: "2026-01-01", to: "2026-12-31", wantDates: []string{"2026-01-05"}},
+		{name: "empty range", pair: cadJPY, from: "2026-03-01", to: "2026-03-31", wantDates: nil},
+		{name: "unknown pair", pair: rate.Pair{Base: "EUR", Target: "JPY"}, from: "2026-01-01", to: "2026-12-31", wantDates: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := client.ReadRange(ctx, tt.pair, tt.from, tt.to)
+
+			assert.NoError(t, err)
+			var dates []string
+			for _, r := range rates {
+				dates = append(dates, r.EffectiveDate)
+			}
+			assert.Equal(t, tt.wantDates, dates)
+		})
+	}
+}
+
+func TestBoltClient_Conflict(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewBoltClient(filepath.Join(t.TempDir(), "rates.db"), time.Second)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	_, stale, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Write(ctx, cadJPY, []*rate.Rate{cadRate("2026-03-18", "112.5")}, stale))
+
+	// versions are per pair, so writing another pair does not conflict
+	_, usdVersion, err := client.Read(ctx, usdJPY)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Write(ctx, usdJPY, []*rate.Rate{}, usdVersion))
+
+	err = client.Write(ctx, cadJPY, []*rate.Rate{cadRate("2026-03-19", "110.22")}, stale)
+
+	var conflict *domainStorage.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	rates, _, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Len(t, rates, 1)
+	assert.Equal(t, "2026-03-18", rates[0].EffectiveDate)
+}
+
+func TestBoltClient_PutRates(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewBoltClient(filepath.Join(t.TempDir(), "rates.db"), time.Second)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	history := []*rate.Rate{cadRate("2026-03-16", "111.1"), cadRate("2026-03-17", "111.8"), cadRate("2026-03-18", "112.5")}
+	assert.NoError(t, client.PutRates(ctx, history, 0))
+	_, version, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+
+	// a new date and a corrected one are put; the limit drops the oldest dates
+	usd := &rate.Rate{EffectiveDate: "2026-03-19", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.08")}
+	corrected := cadRate("2026-03-18", "112.4")
+	assert.NoError(t, client.PutRates(ctx, []*rate.Rate{cadRate("2026-03-19", "110.22"), corrected, usd}, 2))
+
+	rates, _, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Equal(t, []*rate.Rate{corrected, cadRate("2026-03-19", "110.22")}, rates)
+	usdRates, _, err := client.Read(ctx, usdJPY)
+	assert.NoError(t, err)
+	assert.Equal(t, []*rate.Rate{usd}, usdRates)
+
+	// putting bumps the version, so a Write based on an earlier read conflicts
+	var conflict *domainStorage.ConflictError
+	assert.ErrorAs(t, client.Write(ctx, cadJPY, history, version), &conflict)
+}
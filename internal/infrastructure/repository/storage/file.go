@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
+)
+
+// FileClient keeps the rate history in a local JSON file, in the same format as GCSClient,
+// so yenup can run without GCP credentials. Writes hold an exclusive lock on "<path>.lock"
+// and replace the file atomically through a temp file and rename, so readers never see a
+// partial file. The history version is a hash of the file content.
+type FileClient struct {
+	path string
+	mu   sync.Mutex // serializes writers within the process; the lock file covers other processes
+}
+
+// NewFileClient creates a new FileClient for the file at path.
+func NewFileClient(path string) *FileClient {
+	return &FileClient{
+		path: path,
+	}
+}
+
+// Read loads the rate history of pair from the file.
+func (f *FileClient) Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, domainStorage.Version, error) {
+	histories, version, err := f.ReadPairs(ctx, []rate.Pair{pair})
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories[pair], version, nil
+}
+
+// ReadPairs loads the rate history of each of pairs from the file in one read.
+func (f *FileClient) ReadPairs(ctx context.Context, pairs []rate.Pair) (map[rate.Pair][]*rate.Rate, domainStorage.Version, error) {
+	histories, version, err := f.readAll()
+	if err != nil {
+		return nil, 0, err
+	}
+	return pickPairs(histories, pairs), version, nil
+}
+
+// Write replaces the rate history of pair. It fails with a *domainStorage.ConflictError
+// when the file changed since version was read.
+func (f *FileClient) Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version domainStorage.Version) error {
+	return f.WritePairs(ctx, map[rate.Pair][]*rate.Rate{pair: rates}, version)
+}
+
+// WritePairs replaces the rate history of every pair in updates with a single file write,
+// under the same version check as Write.
+func (f *FileClient) WritePairs(ctx context.Context, updates map[rate.Pair][]*rate.Rate, version domainStorage.Version) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	unlock, err := lockFile(f.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer unlock()
+
+	histories, current, err := f.readAll()
+	if err != nil {
+		return err
+	}
+	if current != version {
+		return conflictError(updates, version)
+	}
+	for pair, rates := range updates {
+		histories[pair.String()] = rates
+	}
+
+	data, err := json.MarshalIndent(histories, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rates: %w", err)
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+// Pairs lists the pairs stored in the file, sorted by name.
+func (f *FileClient) Pairs(ctx context.Context) ([]rate.Pair, error) {
+	histories, _, err := f.readAll()
+	if err != nil {
+		return nil, err
+	}
+	return sortedPairs(histories)
+}
+
+// readAll loads every pair's history and the version of the file; a missing file is an empty history
+func (f *FileClient) readAll() (map[string][]*rate.Rate, domainStorage.Version, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string][]*rate.Rate{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	histories, err := decodeHistories(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories, hashVersion(data), nil
+}
+
+// hashVersion derives a history version from data, such as file content or an ETag;
+// identical data means nothing changed
+func hashVersion(data []byte) domainStorage.Version {
+	h := fnv.New64a()
+	h.Write(data)
+	// keep it non-zero, zero is reserved for a missing history
+	return domainStorage.Version(h.Sum64()>>1 | 1)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	// a no-op once the rename succeeded
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	cadJPY = rate.Pair{Base: "CAD", Target: "JPY"}
+	usdJPY = rate.Pair{Base: "USD", Target: "JPY"}
+)
+
+func TestFileClient_ReadWrite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history", "rates.json")
+	client := NewFileClient(path)
+
+	// a missing file is an empty history at version 0
+	rates, version, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Empty(t, rates)
+	assert.Equal(t, domainStorage.Version(0), version)
+
+	cad := []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}}
+	assert.NoError(t, client.Write(ctx, cadJPY, cad, version))
+
+	_, version, err = client.Read(ctx, usdJPY)
+	assert.NoError(t, err)
+	usd := []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.08")}}
+	assert.NoError(t, client.Write(ctx, usdJPY, usd, version))
+
+	// each pair keeps its own history
+	got, _, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.Equal(t, cad, got)
+	pairs, err := client.Pairs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []rate.Pair{cadJPY, usdJPY}, pairs)
+
+	// only the history file and its lock remain; temp files are renamed away
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"rates.json", "rates.json.lock"}, names)
+}
+
+func TestFileClient_Conflict(t *testing.T) {
+	ctx := context.Background()
+	client := NewFileClient(filepath.Join(t.TempDir(), "rates.json"))
+
+	_, stale, err := client.Read(ctx, cadJPY)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Write(ctx, usdJPY, []*rate.Rate{}, stale))
+
+	err = client.Write(ctx, cadJPY, []*rate.Rate{}, stale)
+
+	var conflict *domainStorage.ConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, cadJPY, conflict.Pair)
+}
+
+func TestFileClient_BatchReadWrite(t *testing.T) {
+	ctx := context.Background()
+	client := NewFileClient(filepath.Join(t.TempDir(), "rates.json"))
+
+	histories, version, err := client.ReadPairs(ctx, []rate.Pair{cadJPY, usdJPY})
+	assert.NoError(t, err)
+	assert.Equal(t, map[rate.Pair][]*rate.Rate{cadJPY: {}, usdJPY: {}}, histories)
+
+	updates := map[rate.Pair][]*rate.Rate{
+		cadJPY: {{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}},
+		usdJPY: {{EffectiveDate: "2026-03-19", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.08")}},
+	}
+	assert.NoError(t, client.WritePairs(ctx, updates, version))
+
+	got, _, err := client.ReadPairs(ctx, []rate.Pair{cadJPY, usdJPY})
+	assert.NoError(t, err)
+	assert.Equal(t, updates, got)
+
+	// the batch was written at the version read before it, which is stale now
+	var conflict *domainStorage.ConflictError
+	assert.ErrorAs(t, client.WritePairs(ctx, updates, version), &conflict)
+	assert.Equal(t, cadJPY, conflict.Pair)
+}
+
+func TestFileClient_ConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rates.json")
+
+	// every writer retries until its pair is stored, like RateChecker does
+	pairs := []rate.Pair{cadJPY, usdJPY, {Base: "EUR", Target: "JPY"}, {Base: "GBP", Target: "JPY"}}
+	var wg sync.WaitGroup
+	for _, pair := range pairs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// separate clients, so only the lock file serializes them
+			client := NewFileClient(path)
+			for {
+				_, version, err := client.Read(ctx, pair)
+				if !assert.NoError(t, err) {
+					return
+				}
+				r := &rate.Rate{EffectiveDate: "2026-03-19", Base: pair.Base, Target: pair.Target, Value: rate.MustParseDecimal("1")}
+				err = client.Write(ctx, pair, []*rate.Rate{r}, version)
+				if err == nil {
+					return
+				}
+				var conflict *domainStorage.ConflictError
+				if !assert.ErrorAs(t, err, &conflict) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := NewFileClient(path).Pairs(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, stored, len(pairs))
+}
@@ -1,18 +1,25 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"sort"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
 
 	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
 )
 
 // GCSClient wraps a GCS bucket handle and provides read/write operations for rate data.
+// All pairs are kept in one JSON object keyed by pair, e.g. {"CAD/JPY": [...], "USD/JPY": [...]}.
+// The object generation is the history version, so writes are conditional on it.
 type GCSClient struct {
 	bucket *storage.BucketHandle
 	object string
@@ -26,39 +33,55 @@ func NewGCSClient(client *storage.Client, bucketName, objectName string) *GCSCli
 	}
 }
 
-// Read fetches rate data from GCS and deserializes it into a slice of Rate.
-func (g *GCSClient) Read(ctx context.Context) ([]*rate.Rate, error) {
-
-	reader, err := g.bucket.Object(g.object).NewReader(ctx)
-	// if the JSON file doesn't exist, return an empty slice
-	if errors.Is(err, storage.ErrObjectNotExist) {
-		return []*rate.Rate{}, nil
-	}
+// Read fetches the rate history of pair from GCS, with the object generation as its version.
+func (g *GCSClient) Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, domainStorage.Version, error) {
+	histories, version, err := g.ReadPairs(ctx, []rate.Pair{pair})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open reader: %w", err)
+		return nil, 0, err
 	}
-	defer reader.Close()
+	return histories[pair], version, nil
+}
 
-	data, err := io.ReadAll(reader)
+// ReadPairs fetches the rate history of each of pairs from GCS in one read, with the object
+// generation as their version.
+func (g *GCSClient) ReadPairs(ctx context.Context, pairs []rate.Pair) (map[rate.Pair][]*rate.Rate, domainStorage.Version, error) {
+	histories, generation, err := g.readAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read GCS: %w", err)
+		return nil, 0, err
 	}
+	return pickPairs(histories, pairs), domainStorage.Version(generation), nil
+}
+
+// Write replaces the rate history of pair and saves every pair back to GCS.
+// The upload only succeeds while the object is still at the generation version was read at;
+// otherwise a *domainStorage.ConflictError is returned.
+func (g *GCSClient) Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version domainStorage.Version) error {
+	return g.WritePairs(ctx, map[rate.Pair][]*rate.Rate{pair: rates}, version)
+}
 
-	var rates []*rate.Rate
-	err = json.Unmarshal(data, &rates)
+// WritePairs replaces the rate history of every pair in updates with a single upload,
+// under the same generation condition as Write.
+func (g *GCSClient) WritePairs(ctx context.Context, updates map[rate.Pair][]*rate.Rate, version domainStorage.Version) error {
+	histories, generation, err := g.readAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal rates: %w", err)
+		return err
+	}
+	if generation != int64(version) {
+		return conflictError(updates, version)
+	}
+	for pair, rates := range updates {
+		histories[pair.String()] = rates
 	}
 
-	return rates, nil
-}
-
-// Write serializes rate data and saves it to GCS.
-func (g *GCSClient) Write(ctx context.Context, rates []*rate.Rate) error {
-	writer := g.bucket.Object(g.object).NewWriter(ctx)
+	conds := storage.Conditions{GenerationMatch: generation}
+	if generation == 0 {
+		// nothing was stored when the history was read, so nobody may have created it since
+		conds = storage.Conditions{DoesNotExist: true}
+	}
+	writer := g.bucket.Object(g.object).If(conds).NewWriter(ctx)
 	writer.ContentType = "application/json"
 
-	rateJSON, err := json.Marshal(rates)
+	rateJSON, err := json.Marshal(histories)
 	if err != nil {
 		return fmt.Errorf("failed to marshal rates: %w", err)
 	}
@@ -67,8 +90,114 @@ func (g *GCSClient) Write(ctx context.Context, rates []*rate.Rate) error {
 	}
 
 	if err := writer.Close(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return conflictError(updates, version)
+		}
 		return fmt.Errorf("failed to close GCS writer: %w", err)
 	}
 
 	return nil
 }
+
+// Pairs lists the pairs stored in GCS, sorted by name.
+func (g *GCSClient) Pairs(ctx context.Context) ([]rate.Pair, error) {
+	histories, _, err := g.readAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sortedPairs(histories)
+}
+
+// readAll fetches every pair's history from GCS and the generation it was read at.
+func (g *GCSClient) readAll(ctx context.Context) (map[string][]*rate.Rate, int64, error) {
+	reader, err := g.bucket.Object(g.object).NewReader(ctx)
+	// if the JSON file doesn't exist, return an empty history at generation 0
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return map[string][]*rate.Rate{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open reader: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read GCS: %w", err)
+	}
+
+	histories, err := decodeHistories(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories, reader.Attrs.Generation, nil
+}
+
+// decodeHistories parses stored history keyed by pair. The flat array written by
+// earlier versions is accepted too and grouped by pair.
+func decodeHistories(data []byte) (map[string][]*rate.Rate, error) {
+	histories := make(map[string][]*rate.Rate)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return histories, nil
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var rates []*rate.Rate
+		if err := json.Unmarshal(data, &rates); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rates: %w", err)
+		}
+		for _, r := range rates {
+			key := r.Pair().String()
+			histories[key] = append(histories[key], r)
+		}
+		return histories, nil
+	}
+
+	if err := json.Unmarshal(data, &histories); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rates: %w", err)
+	}
+	return histories, nil
+}
+
+// sortedPairs parses the keys of histories, sorted by name
+func sortedPairs(histories map[string][]*rate.Rate) ([]rate.Pair, error) {
+	keys := make([]string, 0, len(histories))
+	for key := range histories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]rate.Pair, 0, len(keys))
+	for _, key := range keys {
+		pair, err := rate.ParsePair(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pair in rate history: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// pickPairs returns the history of each of pairs; a pair without history gets an empty slice
+func pickPairs(histories map[string][]*rate.Rate, pairs []rate.Pair) map[rate.Pair][]*rate.Rate {
+	picked := make(map[rate.Pair][]*rate.Rate, len(pairs))
+	for _, pair := range pairs {
+		rates, ok := histories[pair.String()]
+		if !ok {
+			rates = []*rate.Rate{}
+		}
+		picked[pair] = rates
+	}
+	return picked
+}
+
+// conflictError reports a conflicting write of updates, naming the first of their pairs
+func conflictError(updates map[rate.Pair][]*rate.Rate, version domainStorage.Version) *domainStorage.ConflictError {
+	var first rate.Pair
+	for pair := range updates {
+		if first == (rate.Pair{}) || pair.String() < first.String() {
+			first = pair
+		}
+	}
+	return &domainStorage.ConflictError{Pair: first, Version: version}
+}
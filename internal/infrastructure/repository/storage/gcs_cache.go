@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	rate "yenup/internal/domain/rate"
+)
+
+// GCSCache is a rate.Cache persisted as a single JSON object in GCS so cached rates
+// survive Cloud Run cold starts. The object is loaded once and kept in memory.
+type GCSCache struct {
+	bucket *storage.BucketHandle
+	object string
+
+	mu      sync.Mutex
+	entries map[string]rate.CacheEntry // nil until loaded
+}
+
+// NewGCSCache creates a new GCSCache with the specified bucket and object.
+func NewGCSCache(client *storage.Client, bucketName, objectName string) *GCSCache {
+	return &GCSCache{
+		bucket: client.Bucket(bucketName),
+		object: objectName,
+	}
+}
+
+// Get returns the entry stored under key, loading the cache object on first use.
+func (g *GCSCache) Get(ctx context.Context, key string) (rate.CacheEntry, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.load(ctx); err != nil {
+		return rate.CacheEntry{}, false, err
+	}
+	entry, ok := g.entries[key]
+	return entry, ok, nil
+}
+
+// Set stores entry under key and writes the cache object back to GCS.
+// Expired entries are dropped on every write.
+func (g *GCSCache) Set(ctx context.Context, key string, entry rate.CacheEntry) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.load(ctx); err != nil {
+		return err
+	}
+	g.entries[key] = entry
+
+	now := time.Now()
+	for k, e := range g.entries {
+		if e.Expired(now) {
+			delete(g.entries, k)
+		}
+	}
+
+	return g.save(ctx)
+}
+
+// load reads the cache object once; a missing object is an empty cache
+func (g *GCSCache) load(ctx context.Context) error {
+	if g.entries != nil {
+		return nil
+	}
+
+	reader, err := g.bucket.Object(g.object).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		g.entries = make(map[string]rate.CacheEntry)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open cache reader: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read cache from GCS: %w", err)
+	}
+
+	entries := make(map[string]rate.CacheEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal cache: %w", err)
+	}
+	g.entries = entries
+	return nil
+}
+
+// save writes all entries to the cache object
+func (g *GCSCache) save(ctx context.Context) error {
+	writer := g.bucket.Object(g.object).NewWriter(ctx)
+	writer.ContentType = "application/json"
+
+	data, err := json.Marshal(g.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write cache json: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close GCS cache writer: %w", err)
+	}
+	return nil
+}
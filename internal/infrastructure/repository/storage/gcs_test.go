@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"testing"
+
+	rate "yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeHistories(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantPairs []rate.Pair
+		wantLen   map[string]int
+		wantErr   bool
+	}{
+		{
+			name:      "success: keyed by pair",
+			data:      `{"CAD/JPY":[{"date":"2026-03-18","base":"CAD","target":"JPY","value":112.5}],"USD/JPY":[]}`,
+			wantPairs: []rate.Pair{{Base: "CAD", Target: "JPY"}, {Base: "USD", Target: "JPY"}},
+			wantLen:   map[string]int{"CAD/JPY": 1, "USD/JPY": 0},
+		},
+		{
+			name: "success: legacy flat array is grouped by pair",
+			data: `[{"date":"2026-03-18","base":"CAD","target":"JPY","value":112.5},` +
+				`{"date":"2026-03-18","base":"USD","target":"JPY","value":157.1},` +
+				`{"date":"2026-03-19","base":"CAD","target":"JPY","value":110.2}]`,
+			wantPairs: []rate.Pair{{Base: "CAD", Target: "JPY"}, {Base: "USD", Target: "JPY"}},
+			wantLen:   map[string]int{"CAD/JPY": 2, "USD/JPY": 1},
+		},
+		{
+			name:      "success: empty object",
+			data:      ``,
+			wantPairs: []rate.Pair{},
+			wantLen:   map[string]int{},
+		},
+		{
+			name:    "error: malformed JSON",
+			data:    `{"CAD/JPY":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histories, err := decodeHistories([]byte(tt.data))
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			pairs, err := sortedPairs(histories)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPairs, pairs)
+			for key, n := range tt.wantLen {
+				assert.Len(t, histories[key], n)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+
+	rate "yenup/internal/domain/rate"
+)
+
+// maxUsageAttempts bounds how often Reserve re-reads the usage object after another
+// instance updated it first
+const maxUsageAttempts = 5
+
+// errUsageChanged means the usage object was written by someone else since it was read
+var errUsageChanged = errors.New("usage object changed since it was read")
+
+// GCSUsageStore is a rate.UsageStore persisted as a single JSON object in GCS so monthly
+// provider usage survives Cloud Run cold starts. The object is kept in memory between calls,
+// and every write is conditional on the generation it was read at, so counts added by
+// other instances are never overwritten.
+type GCSUsageStore struct {
+	bucket *storage.BucketHandle
+	object string
+
+	mu         sync.Mutex
+	counts     map[string]int // "provider|YYYY-MM" -> calls; nil until loaded
+	generation int64          // generation counts were read at; 0 when the object did not exist
+}
+
+// NewGCSUsageStore creates a new GCSUsageStore with the specified bucket and object.
+func NewGCSUsageStore(client *storage.Client, bucketName, objectName string) *GCSUsageStore {
+	return &GCSUsageStore{
+		bucket: client.Bucket(bucketName),
+		object: objectName,
+	}
+}
+
+// Reserve records n more calls unless that would take the total past limit, and writes the
+// usage object back to GCS. When another instance wrote the object first, it is re-read and
+// the limit checked again.
+func (g *GCSUsageStore) Reserve(ctx context.Context, provider, month string, n, limit int) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := provider + "|" + month
+	for attempt := 1; ; attempt++ {
+		if err := g.load(ctx); err != nil {
+			return 0, err
+		}
+		used := g.counts[key]
+		if limit > 0 && used+n > limit {
+			return used, fmt.Errorf("%w: %s used %d of %d calls in %s", rate.ErrQuotaUsedUp, provider, used, limit, month)
+		}
+
+		err := g.save(ctx, key, used+n)
+		if err == nil {
+			return used + n, nil
+		}
+		if !errors.Is(err, errUsageChanged) || attempt >= maxUsageAttempts {
+			return 0, err
+		}
+		// drop the stale counts so the next attempt re-reads them
+		g.counts = nil
+	}
+}
+
+// load reads the usage object unless it is already loaded; a missing object means no usage yet
+func (g *GCSUsageStore) load(ctx context.Context) error {
+	if g.counts != nil {
+		return nil
+	}
+
+	reader, err := g.bucket.Object(g.object).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		g.counts = make(map[string]int)
+		g.generation = 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open usage reader: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read usage from GCS: %w", err)
+	}
+
+	counts := make(map[string]int)
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return fmt.Errorf("failed to unmarshal usage: %w", err)
+	}
+	g.counts = counts
+	g.generation = reader.Attrs.Generation
+	return nil
+}
+
+// save writes the counters with key set to used, as long as the object is still at the
+// generation it was read at. The in-memory counts are only updated once the write succeeded.
+func (g *GCSUsageStore) save(ctx context.Context, key string, used int) error {
+	counts := maps.Clone(g.counts)
+	counts[key] = used
+
+	conds := storage.Conditions{GenerationMatch: g.generation}
+	if g.generation == 0 {
+		conds = storage.Conditions{DoesNotExist: true}
+	}
+	writer := g.bucket.Object(g.object).If(conds).NewWriter(ctx)
+	writer.ContentType = "application/json"
+
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write usage json: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+			return errUsageChanged
+		}
+		return fmt.Errorf("failed to close GCS usage writer: %w", err)
+	}
+	g.counts = counts
+	g.generation = writer.Attrs().Generation
+	return nil
+}
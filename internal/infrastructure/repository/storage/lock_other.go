@@ -0,0 +1,10 @@
+//go:build !unix
+
+package storage
+
+// lockFile is a no-op where flock is unavailable. Writers in the same process are still
+// serialized by FileClient, but nothing orders the version check and the rename of another
+// process, so its concurrent write can be lost. Only one process may write the file there.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	rate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
+)
+
+// S3Options configures an S3Client
+type S3Options struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000
+	Region          string
+	Bucket          string
+	Key             string // object key of the rate history
+	PathStyle       bool   // address the bucket as endpoint/bucket/key instead of bucket.endpoint/key
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // only for temporary credentials
+}
+
+// S3Client keeps the rate history in one object of an S3-compatible store (AWS S3, MinIO, ...),
+// in the same JSON format as GCSClient. Requests use the S3 REST API signed with SigV4.
+// The version of the history is derived from the object ETag, and writes are made
+// conditional on it with If-Match / If-None-Match.
+//
+// The credentials need s3:GetObject and s3:PutObject on the key, and s3:ListBucket on the
+// bucket: without ListBucket, AWS answers a missing key with 403 AccessDenied instead of
+// 404 NoSuchKey, so the first run cannot tell an empty history from a denied read.
+type S3Client struct {
+	objectURL *url.URL
+	region    string
+	creds     s3Credentials
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Client creates a new S3Client. client defaults to http.DefaultClient.
+func NewS3Client(opts S3Options, client *http.Client) (*S3Client, error) {
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" || opts.Key == "" {
+		return nil, fmt.Errorf("S3 bucket and key are required")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	objectURL := *endpoint
+	key := strings.TrimPrefix(opts.Key, "/")
+	if opts.PathStyle {
+		objectURL.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + opts.Bucket + "/" + key
+	} else {
+		objectURL.Host = opts.Bucket + "." + endpoint.Host
+		objectURL.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + key
+	}
+	// send the path exactly as it is signed
+	objectURL.RawPath = uriEncode(objectURL.Path, false)
+
+	return &S3Client{
+		objectURL: &objectURL,
+		region:    opts.Region,
+		creds: s3Credentials{
+			AccessKeyID:     opts.AccessKeyID,
+			SecretAccessKey: opts.SecretAccessKey,
+			SessionToken:    opts.SessionToken,
+		},
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+// Read fetches the rate history of pair; a missing object is an empty history at version 0.
+func (s *S3Client) Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, domainStorage.Version, error) {
+	histories, version, err := s.ReadPairs(ctx, []rate.Pair{pair})
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories[pair], version, nil
+}
+
+// ReadPairs fetches the rate history of each of pairs in one read.
+func (s *S3Client) ReadPairs(ctx context.Context, pairs []rate.Pair) (map[rate.Pair][]*rate.Rate, domainStorage.Version, error) {
+	histories, etag, err := s.readAll(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pickPairs(histories, pairs), etagVersion(etag), nil
+}
+
+// Write replaces the rate history of pair. The upload is conditional on the ETag the
+// history was read at, so a concurrent update fails with a *domainStorage.ConflictError.
+func (s *S3Client) Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version domainStorage.Version) error {
+	return s.WritePairs(ctx, map[rate.Pair][]*rate.Rate{pair: rates}, version)
+}
+
+// WritePairs replaces the rate history of every pair in updates with a single upload,
+// under the same ETag condition as Write.
+func (s *S3Client) WritePairs(ctx context.Context, updates map[rate.Pair][]*rate.Rate, version domainStorage.Version) error {
+	histories, etag, err := s.readAll(ctx)
+	if err != nil {
+		return err
+	}
+	if etagVersion(etag) != version {
+		return conflictError(updates, version)
+	}
+	for pair, rates := range updates {
+		histories[pair.String()] = rates
+	}
+
+	body, err := json.Marshal(histories)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rates: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if etag == "" {
+		req.Header.Set("If-None-Match", "*")
+	} else {
+		req.Header.Set("If-Match", etag)
+	}
+	signV4(req, sha256Hex(body), s.creds, s.region, "s3", s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write S3 object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		// 409 is returned when a conditional write races another one
+		return conflictError(updates, version)
+	default:
+		return fmt.Errorf("failed to write S3 object: %w", readS3Error(resp))
+	}
+}
+
+// Pairs lists the pairs stored in the object, sorted by name.
+func (s *S3Client) Pairs(ctx context.Context) ([]rate.Pair, error) {
+	histories, _, err := s.readAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sortedPairs(histories)
+}
+
+// readAll fetches every pair's history and the object ETag; a missing object has no ETag
+func (s *S3Client) readAll(ctx context.Context) (map[string][]*rate.Rate, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	signV4(req, emptyPayloadHash, s.creds, s.region, "s3", s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read S3 object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		s3Err := readS3Error(resp)
+		// if the object doesn't exist, return an empty history; a missing bucket is still an error
+		if s3Err.Code == "NoSuchKey" || (resp.StatusCode == http.StatusNotFound && s3Err.Code == "") {
+			return map[string][]*rate.Rate{}, "", nil
+		}
+		if s3Err.Code == "AccessDenied" {
+			return nil, "", fmt.Errorf("failed to read S3 object (a missing object is denied without s3:ListBucket): %w", s3Err)
+		}
+		return nil, "", fmt.Errorf("failed to read S3 object: %w", s3Err)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read S3 object: %w", err)
+	}
+	histories, err := decodeHistories(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return histories, resp.Header.Get("ETag"), nil
+}
+
+// etagVersion turns an ETag into a history version; no ETag means no object
+func etagVersion(etag string) domainStorage.Version {
+	if etag == "" {
+		return 0
+	}
+	return hashVersion([]byte(etag))
+}
+
+// s3Error is the XML error document returned by S3
+type s3Error struct {
+	Status  string `xml:"-"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// readS3Error parses the error document of a failed response; HEAD-like responses have none
+func readS3Error(resp *http.Response) *s3Error {
+	s3Err := &s3Error{Status: resp.Status}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err == nil && len(data) > 0 {
+		_ = xml.Unmarshal(data, s3Err)
+	}
+	return s3Err
+}
@@ -1,11 +1,19 @@
 package registry
 
 import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
 	"yenup/internal/config"
 	domainRate "yenup/internal/domain/rate"
+	domainStorage "yenup/internal/domain/storage"
 	"yenup/internal/handler"
+	healthHandler "yenup/internal/handler/health"
 	rateHandler "yenup/internal/handler/rate"
 	reportHandler "yenup/internal/handler/report"
+	"yenup/internal/infrastructure/httpclient"
 	notifierRepo "yenup/internal/infrastructure/repository/notifier"
 	rateRepo "yenup/internal/infrastructure/repository/rate"
 	storageRepo "yenup/internal/infrastructure/repository/storage"
@@ -19,34 +27,214 @@ type Registry struct {
 	AppHandler *handler.Handler
 }
 
+// NewRegistry wires the application. gcsClient may be nil when cfg.UsesGCS() is false.
 func NewRegistry(cfg *config.Config, gcsClient *storage.Client) (*Registry, error) {
+	if gcsClient == nil && cfg.UsesGCS() {
+		return nil, fmt.Errorf("a GCS client is required by the configured backends")
+	}
+
+	// httpClient is shared by the rate fetchers, Slack and S3 storage. Proxy, TLS, pooling and the
+	// response size limit come from OUTBOUND_* config, transient failures are retried
+	// per RETRY_* config, and per-call deadlines for providers come from cfg.APITimeout.
+	httpClient, err := httpclient.New(httpclient.Options{
+		ProxyURL:            cfg.OutboundProxyURL,
+		CABundle:            cfg.OutboundCABundle,
+		UserAgent:           cfg.OutboundUserAgent,
+		TLSMinVersion:       cfg.OutboundTLSMinVersion,
+		MaxIdleConns:        cfg.OutboundMaxIdleConns,
+		MaxIdleConnsPerHost: cfg.OutboundMaxIdlePerHost,
+		MaxConnsPerHost:     cfg.OutboundMaxConnsPerHost,
+		IdleConnTimeout:     cfg.OutboundIdleConnTimeout,
+		MaxResponseBytes:    cfg.OutboundMaxResponseBytes,
+		Retry: httpclient.RetryPolicy{
+			MaxAttempts: cfg.RetryMaxAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+			Jitter:      cfg.RetryJitter,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build outbound HTTP client: %w", err)
+	}
+
+	// precision is applied wherever a rate has to be derived by division
+	roundingMode, err := domainRate.ParseRoundingMode(cfg.DecimalRounding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DECIMAL_ROUNDING: %w", err)
+	}
+	precision := domainRate.Precision{Scale: int32(cfg.DecimalScale), Mode: roundingMode}
+
+	// storageClient provides read/write access to the rate history of each pair.
+	var storageClient domainStorage.Client
+	switch cfg.StorageBackend {
+	case "gcs":
+		storageClient = storageRepo.NewGCSClient(gcsClient, cfg.GCSBucketName, cfg.GCSObjectName)
+	case "file":
+		storageClient = storageRepo.NewFileClient(cfg.StorageFilePath)
+	case "s3":
+		storageClient, err = storageRepo.NewS3Client(storageRepo.S3Options{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			Key:             cfg.S3Key,
+			PathStyle:       cfg.S3PathStyle,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			SessionToken:    cfg.S3SessionToken,
+		}, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 storage config: %w", err)
+		}
+	case "bolt":
+		// the database stays open, and its file locked, for the life of the process
+		storageClient, err = storageRepo.NewBoltClient(cfg.StorageBoltPath, 10*time.Second)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND: %q", cfg.StorageBackend)
+	}
+
+	slackNotifier := notifierRepo.NewSlackNotifier(cfg.SlackWebhookURL, httpClient)
+
+	// usageStore counts monthly calls to providers that have a quota
+	var usageStore domainRate.UsageStore
+	switch cfg.UsageBackend {
+	case "memory":
+		usageStore = rateRepo.NewMemoryUsageStore()
+	case "gcs":
+		usageStore = storageRepo.NewGCSUsageStore(gcsClient, cfg.GCSBucketName, cfg.UsageGCSObjectName)
+	default:
+		return nil, fmt.Errorf("unknown USAGE_BACKEND: %q", cfg.UsageBackend)
+	}
 
-	// storageClient provides read/write access to rate data stored in GCS.
-	storageClient := storageRepo.NewGCSClient(gcsClient, cfg.GCSBucketName, cfg.GCSObjectName)
+	// Select rate fetchers based on API_PROVIDERS (or API_PROVIDER) config
+	// Each provider gets its own circuit breaker so a tripped one fails fast.
+	var providers []rateRepo.NamedFetcher
+	var statusReporters []domainRate.StatusReporter
+	var currencyListers []domainRate.CurrencyLister
+	for _, name := range cfg.APIProviders {
+		fetcher, err := newRateFetcher(name, cfg, httpClient, precision)
+		if err != nil {
+			return nil, err
+		}
+		_, canList := fetcher.(domainRate.CurrencyLister)
+		// Meter providers with a monthly quota or an outbound rate limit.
+		quota, perSecond := int(cfg.ProviderQuotas[name]), cfg.ProviderRateLimits[name]
+		if quota > 0 || perSecond > 0 {
+			var limiter *rateRepo.TokenBucket
+			if perSecond > 0 {
+				limiter = rateRepo.NewTokenBucket(perSecond, max(1, int(perSecond)))
+			}
+			fetcher = rateRepo.NewQuotaFetcher(name, fetcher, limiter, usageStore, quota, cfg.QuotaWarnRatio, slackNotifier)
+		}
+		// each provider's currency list is cached for a day, and its requests are metered too
+		if canList {
+			currencyListers = append(currencyListers, rateRepo.NewCachingCurrencyLister(fetcher.(domainRate.CurrencyLister), 24*time.Hour))
+		}
+		if cfg.BreakerThreshold > 0 {
+			breaker := rateRepo.NewBreakerFetcher(name, fetcher, cfg.BreakerThreshold, cfg.BreakerCooldown)
+			statusReporters = append(statusReporters, breaker)
+			fetcher = breaker
+		}
+		providers = append(providers, rateRepo.NamedFetcher{Name: name, Fetcher: fetcher})
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no rate provider configured")
+	}
+
+	// Currencies are validated against every provider's list, so only when all of them can list.
+	var currencyLister domainRate.CurrencyLister
+	switch {
+	case len(currencyListers) < len(providers):
+	case len(currencyListers) == 1:
+		currencyLister = currencyListers[0]
+	default:
+		currencyLister = rateRepo.NewCombinedCurrencyLister(currencyListers...)
+	}
 
-	// Select rate fetcher based on API_PROVIDER config
-	var rateFetcher domainRate.RateFetcher
-	if cfg.APIProvider == "frankfurter" {
-		rateFetcher = rateRepo.NewFrankfurterFetcher(cfg.FrankfurterAPIURL)
-	} else {
-		rateFetcher = rateRepo.NewExchangeRatesFetcher(cfg.ExchangeRateAPIKey, cfg.ExchangeRateAPIURL)
+	// A single provider is used as is; several are combined according to API_STRATEGY.
+	var rateFetcher domainRate.RateFetcher = providers[0].Fetcher
+	if len(providers) > 1 {
+		switch cfg.APIStrategy {
+		case "failover":
+			rateFetcher = rateRepo.NewFailoverFetcher(providers...)
+		case "consensus":
+			rateFetcher = rateRepo.NewConsensusFetcher(cfg.ConsensusTolerance, providers...)
+		default:
+			return nil, fmt.Errorf("unknown API_STRATEGY: %q", cfg.APIStrategy)
+		}
 	}
 
-	slackNotifier := notifierRepo.NewSlackNotifier(cfg.SlackWebhookURL)
+	// Derive pairs the providers do not quote by inversion, and through a pivot currency when pivots are set.
+	var pivots []domainRate.Currency
+	for _, code := range cfg.TriangulationPivots {
+		pivot, err := domainRate.ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRIANGULATION_PIVOTS: %w", err)
+		}
+		pivots = append(pivots, pivot)
+	}
+	rateFetcher = rateRepo.NewTriangulatingFetcher(rateFetcher, precision, pivots...)
+
+	// Cache rates in front of the providers; past publications are cached forever, the rest for CACHE_TTL.
+	var rateCache domainRate.Cache
+	switch cfg.CacheBackend {
+	case "memory":
+		rateCache = rateRepo.NewMemoryCache()
+	case "gcs":
+		rateCache = storageRepo.NewGCSCache(gcsClient, cfg.GCSBucketName, cfg.CacheGCSObjectName)
+	case "none":
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND: %q", cfg.CacheBackend)
+	}
+	// The file provider is re-read whenever the file changes, so caching it would only hide edits.
+	fileOnly := !slices.ContainsFunc(cfg.APIProviders, func(name string) bool { return name != rateRepo.ProviderFile })
+	if rateCache != nil && !fileOnly {
+		rateFetcher = rateRepo.NewCachingFetcher(rateFetcher, rateCache, cfg.CacheTTL)
+	}
 
 	// usecase
 	rateUsecase := usecase.NewRateChecker(storageClient, rateFetcher, slackNotifier)
+	rateUsecase.HistoryLimit = cfg.HistoryLimit
 	reportUsecase := usecase.NewWeeklyReporter(storageClient, slackNotifier)
+	healthUsecase := usecase.NewHealthChecker(statusReporters...)
+	currencyUsecase := usecase.NewCurrencyChecker(currencyLister)
 
 	// handler
-	rateHandler := rateHandler.NewRateHandler(rateUsecase)
+	rateHandler := rateHandler.NewRateHandler(rateUsecase, currencyUsecase)
+	rateHandler.Rounding = roundingMode
 	reportHandler := reportHandler.NewReportHandler(reportUsecase)
+	healthHandler := healthHandler.NewHealthHandler(healthUsecase)
 
 	// app handler
-	appHandler := handler.NewHandler(rateHandler, reportHandler)
+	appHandler := handler.NewHandler(rateHandler, reportHandler, healthHandler)
 
 	return &Registry{
 		config:     cfg,
 		AppHandler: appHandler,
 	}, nil
 }
+
+// newRateFetcher builds the rate fetcher registered under name
+func newRateFetcher(name string, cfg *config.Config, httpClient *http.Client, precision domainRate.Precision) (domainRate.RateFetcher, error) {
+	switch name {
+	case rateRepo.ProviderFrankfurter:
+		return rateRepo.NewFrankfurterFetcher(cfg.FrankfurterAPIURL, httpClient, cfg.APITimeout), nil
+	case rateRepo.ProviderExchangeRates:
+		return rateRepo.NewExchangeRatesFetcher(cfg.ExchangeRateAPIKey, cfg.ExchangeRateAPIURL, httpClient, cfg.APITimeout, precision), nil
+	case rateRepo.ProviderECB:
+		return rateRepo.NewECBFetcher(cfg.ECBAPIURL, httpClient, cfg.APITimeout, precision), nil
+	case rateRepo.ProviderBankOfCanada:
+		return rateRepo.NewValetFetcher(cfg.ValetAPIURL, httpClient, cfg.APITimeout), nil
+	case rateRepo.ProviderFile:
+		return rateRepo.NewFileFetcher(cfg.RateFilePath), nil
+	case rateRepo.ProviderCommand:
+		if cfg.RateCommand == "" {
+			return nil, fmt.Errorf("RATE_COMMAND is required for the %s provider", name)
+		}
+		return rateRepo.NewCommandFetcher(cfg.RateCommand, cfg.RateCommandInput, cfg.RateCommandTimeout, cfg.RateCommandMaxBytes), nil
+	default:
+		return nil, fmt.Errorf("unknown rate provider: %q", name)
+	}
+}
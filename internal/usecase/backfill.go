@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"yenup/internal/domain/rate"
+	"yenup/internal/domain/storage"
+)
+
+// ErrBackfillUnsupported means the configured fetcher chain cannot return a time series
+var ErrBackfillUnsupported = errors.New("rate fetcher cannot fetch a date range")
+
+// ErrBackfillExceedsHistoryLimit means a backfill fetched more rates than HistoryLimit keeps per pair
+var ErrBackfillExceedsHistoryLimit = errors.New("backfill range holds more rates than the history limit keeps")
+
+// BackfillHistory fetches base/target for every published day between from and to
+// (inclusive, YYYY-MM-DD) in one provider call where possible, and merges the rates
+// into the stored history with a single write. A range with more rates than HistoryLimit
+// keeps is rejected with ErrBackfillExceedsHistoryLimit instead of being trimmed.
+// It returns how many of the fetched rates are stored afterwards; fewer than fetched
+// when they are older than the dates HistoryLimit keeps.
+func (r *RateChecker) BackfillHistory(ctx context.Context, base, target rate.Currency, from, to string) (int, error) {
+	ranger, ok := r.Fetcher.(rate.RangeFetcher)
+	if !ok {
+		return 0, ErrBackfillUnsupported
+	}
+
+	fetched, err := ranger.FetchRange(ctx, from, to, base, target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch rates between %s and %s: %w", from, to, err)
+	}
+	if len(fetched) == 0 {
+		return 0, nil
+	}
+	if r.HistoryLimit > 0 && len(fetched) > r.HistoryLimit {
+		return 0, fmt.Errorf("%w: %d rates between %s and %s, %d kept per pair",
+			ErrBackfillExceedsHistoryLimit, len(fetched), from, to, r.HistoryLimit)
+	}
+
+	rates := make([]*rate.Rate, len(fetched))
+	for i := range fetched {
+		rates[i] = &fetched[i]
+	}
+	if _, err := r.saveRates(ctx, rates); err != nil {
+		return 0, err
+	}
+	return r.countStored(ctx, rate.Pair{Base: base, Target: target}, fetched)
+}
+
+// countStored reports how many of the dates of rates are in the stored history of pair
+func (r *RateChecker) countStored(ctx context.Context, pair rate.Pair, rates []rate.Rate) (int, error) {
+	from, to := rates[0].EffectiveDate, rates[0].EffectiveDate
+	for _, rt := range rates[1:] {
+		from, to = min(from, rt.EffectiveDate), max(to, rt.EffectiveDate)
+	}
+
+	var stored []*rate.Rate
+	var err error
+	if ranger, ok := r.StorageClient.(storage.RangeReader); ok {
+		stored, err = ranger.ReadRange(ctx, pair, from, to)
+	} else {
+		stored, _, err = r.StorageClient.Read(ctx, pair)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rate history: %w", err)
+	}
+
+	dates := make(map[string]bool, len(stored))
+	for _, s := range stored {
+		dates[s.EffectiveDate] = true
+	}
+	count := 0
+	for _, rt := range rates {
+		if dates[rt.EffectiveDate] {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackfillHistory(t *testing.T) {
+	series := []rate.Rate{
+		{EffectiveDate: "2026-03-16", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("109.10")},
+		{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("109.80")},
+		{EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")},
+	}
+
+	tests := []struct {
+		name      string
+		fetcher   rate.RateFetcher
+		stored    []*rate.Rate
+		limit     int
+		wantSaved int
+		wantDates []string
+		wantErr   error
+	}{
+		{
+			name:      "success: older rates are merged in date order",
+			fetcher:   &MockRangeFetcher{series: series},
+			stored:    []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}},
+			wantSaved: 3,
+			wantDates: []string{"2026-03-16", "2026-03-17", "2026-03-18", "2026-03-19"},
+		},
+		{
+			name:      "success: only the rates the history limit keeps are counted",
+			fetcher:   &MockRangeFetcher{series: series},
+			stored:    []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}},
+			limit:     3,
+			wantSaved: 2,
+			wantDates: []string{"2026-03-17", "2026-03-18", "2026-03-19"},
+		},
+		{
+			name:    "error: more rates than the history limit keeps",
+			fetcher: &MockRangeFetcher{series: series},
+			stored:  []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}},
+			limit:   2,
+			wantErr: ErrBackfillExceedsHistoryLimit,
+		},
+		{
+			name:      "success: nothing published in the range",
+			fetcher:   &MockRangeFetcher{},
+			wantSaved: 0,
+		},
+		{
+			name:    "error: fetcher cannot fetch a range",
+			fetcher: &MockFetcher{},
+			wantErr: ErrBackfillUnsupported,
+		},
+		{
+			name:    "error: provider unavailable",
+			fetcher: &MockRangeFetcher{MockFetcher: MockFetcher{err: rate.ErrProviderUnavailable}},
+			wantErr: rate.ErrProviderUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &MockStorageClient{rates: tt.stored}
+			checker := NewRateChecker(storage, tt.fetcher, &MockNotifier{})
+			checker.HistoryLimit = tt.limit
+
+			saved, err := checker.BackfillHistory(context.Background(), "CAD", "JPY", "2026-03-16", "2026-03-18")
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				assert.Zero(t, storage.writeAttempts)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSaved, saved)
+			var dates []string
+			for _, r := range storage.writtenRates {
+				dates = append(dates, r.EffectiveDate)
+			}
+			assert.Equal(t, tt.wantDates, dates)
+			if tt.wantSaved > 0 {
+				assert.Equal(t, 1, storage.writeAttempts)
+			}
+			if rf, ok := tt.fetcher.(*MockRangeFetcher); ok {
+				assert.Equal(t, "2026-03-16", rf.from)
+				assert.Equal(t, "2026-03-18", rf.to)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"yenup/internal/domain/notifier"
@@ -12,21 +14,50 @@ import (
 
 // RateCheckUsecase is the interface for the rate check usecase
 type RateCheckUsecase interface {
-	CheckRates(ctx context.Context, base, target string, forceNotify bool) (*CheckRateResult, error)
+	CheckRates(ctx context.Context, base, target rate.Currency, forceNotify bool) (*CheckRateResult, error)
+	CheckMultipleRates(ctx context.Context, base rate.Currency, targets []rate.Currency, forceNotify bool) ([]*CheckRateResult, error)
+	BackfillHistory(ctx context.Context, base, target rate.Currency, from, to string) (int, error)
 }
 
 // CheckRateResult is the result of checking the rate
 type CheckRateResult struct {
-	TodayRate     float64
-	YesterdayRate float64
+	Base          rate.Currency
+	Target        rate.Currency
+	TodayRate     rate.Decimal
+	YesterdayRate rate.Decimal
 	IsNotified    bool
+	Provider      string        // provider that answered today's rate
+	Derived       bool          // today's rate was inverted or triangulated
+	Pivot         rate.Currency // intermediate currency of a triangulated rate
+
+	// Set when providers were cross-checked
+	Spread             float64 // maximum relative spread between providers for today's rate
+	DataQualityWarning bool    // providers disagreed beyond tolerance; the JPY alert was replaced by a warning
+
+	TodayDate     string // effective date of today's rate
+	YesterdayDate string // effective date of the rate it was compared with
+	MarketClosed  bool   // nothing was published since the last stored rate; the JPY alert is skipped unless forced
 }
 
+// maxLookbackDays bounds how far back the previous distinct effective date is searched,
+// which covers weekends and long holiday closures
+const maxLookbackDays = 7
+
+// maxSaveAttempts bounds how often a history write is redone after a concurrent update
+const maxSaveAttempts = 5
+
+// maxDate is later than any effective date, to read a history up to its end
+const maxDate = "9999-12-31"
+
+// DefaultHistoryLimit is how many days of rates are kept per pair unless HistoryLimit is changed
+const DefaultHistoryLimit = 7
+
 // RateChecker is the usecase for checking the rate
 type RateChecker struct {
 	StorageClient storage.Client
 	Fetcher       rate.RateFetcher
 	Notifier      notifier.Notifier
+	HistoryLimit  int // days of rates kept per pair; 0 keeps the whole history
 }
 
 func NewRateChecker(storageClient storage.Client, fetcher rate.RateFetcher, notifier notifier.Notifier) *RateChecker {
@@ -34,64 +65,198 @@ func NewRateChecker(storageClient storage.Client, fetcher rate.RateFetcher, noti
 		StorageClient: storageClient,
 		Fetcher:       fetcher,
 		Notifier:      notifier,
+		HistoryLimit:  DefaultHistoryLimit,
+	}
+}
+
+func (r *RateChecker) CheckRates(ctx context.Context, base, target rate.Currency, forceNotify bool) (*CheckRateResult, error) {
+	results, err := r.CheckMultipleRates(ctx, base, []rate.Currency{target}, forceNotify)
+	if err != nil {
+		return nil, err
 	}
+	return results[0], nil
 }
 
-func (r *RateChecker) CheckRates(ctx context.Context, base, target string, forceNotify bool) (*CheckRateResult, error) {
-	today := time.Now()
-	yesterday := today.AddDate(0, 0, -1)
-	todayStr := today.Format("2006-01-02")
-	yesterdayStr := yesterday.Format("2006-01-02")
+// CheckMultipleRates checks base against every target. When the fetcher implements
+// rate.BatchFetcher, all targets for a date are fetched in a single provider call.
+// Today's rates are compared with the previous distinct effective date, so a weekend
+// or holiday never compares the last business day with itself. The market counts as closed
+// only when nothing was published for today and the latest rate is already stored, so a check
+// that runs before today's publication still alerts on the latest business day once.
+func (r *RateChecker) CheckMultipleRates(ctx context.Context, base rate.Currency, targets []rate.Currency, forceNotify bool) ([]*CheckRateResult, error) {
+	todayStr := time.Now().Format("2006-01-02")
 
 	// Get rates from repository
-	todayRate, err := r.Fetcher.FetchRate(todayStr, base, target)
+	todayRates, err := r.fetchRates(ctx, todayStr, base, targets)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch today's rate: %w", err)
 	}
-	yesterdayRate, err := r.Fetcher.FetchRate(yesterdayStr, base, target)
+	yesterdayRates, err := r.fetchPreviousRates(ctx, todayStr, todayRates, base, targets)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch yesterday's rate: %w", err)
 	}
 
-	// read the JSON file to get rates information
-	rates, err := r.StorageClient.Read(ctx)
+	// update each pair's history; a rate already stored by an earlier check was alerted on before
+	newRates := make([]*rate.Rate, len(todayRates))
+	for i := range todayRates {
+		newRates[i] = &todayRates[i]
+	}
+	lastDates, err := r.saveRates(ctx, newRates)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read rate history: %w", err)
+		return nil, err
 	}
 
-	// update the JSON file
-	if err := r.saveRates(ctx, &todayRate, rates); err != nil {
-		return nil, err
+	results := make([]*CheckRateResult, 0, len(targets))
+	for i := range todayRates {
+		marketClosed := todayRates[i].EffectiveDate < todayRates[i].RequestedDate && todayRates[i].EffectiveDate <= lastDates[todayRates[i].Pair()]
+		result, err := r.notifyIfStronger(todayRates[i], yesterdayRates[i], marketClosed, forceNotify)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// fetchRates fetches base against every target for date, in the order of targets
+func (r *RateChecker) fetchRates(ctx context.Context, date string, base rate.Currency, targets []rate.Currency) ([]rate.Rate, error) {
+	if batch, ok := r.Fetcher.(rate.BatchFetcher); ok && len(targets) > 1 {
+		return batch.FetchRates(ctx, date, base, targets)
+	}
+
+	rates := make([]rate.Rate, 0, len(targets))
+	for _, target := range targets {
+		rt, err := r.Fetcher.FetchRate(ctx, date, base, target)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rt)
+	}
+	return rates, nil
+}
+
+// fetchPreviousRates fetches the rates published on the effective date before today's.
+// It starts the day before the earliest effective date of todayRates and steps back
+// while the provider still answers with a date that is not older than today's.
+func (r *RateChecker) fetchPreviousRates(ctx context.Context, todayStr string, todayRates []rate.Rate, base rate.Currency, targets []rate.Currency) ([]rate.Rate, error) {
+	date, err := time.Parse("2006-01-02", earliestEffectiveDate(todayRates, todayStr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective date: %w", err)
+	}
+
+	for range maxLookbackDays {
+		date = date.AddDate(0, 0, -1)
+		rates, err := r.fetchRates(ctx, date.Format("2006-01-02"), base, targets)
+		if err != nil {
+			return nil, err
+		}
+		if olderThan(rates, todayRates) {
+			return rates, nil
+		}
+		if earliest, err := time.Parse("2006-01-02", earliestEffectiveDate(rates, "")); err == nil && earliest.Before(date) {
+			date = earliest
+		}
+	}
+	return nil, fmt.Errorf("no earlier effective date within %d days of %s", maxLookbackDays, todayStr)
+}
+
+// earliestEffectiveDate returns the oldest effective date in rates, or fallback when none is set
+func earliestEffectiveDate(rates []rate.Rate, fallback string) string {
+	earliest := ""
+	for _, rt := range rates {
+		if rt.EffectiveDate != "" && (earliest == "" || rt.EffectiveDate < earliest) {
+			earliest = rt.EffectiveDate
+		}
 	}
+	if earliest == "" {
+		return fallback
+	}
+	return earliest
+}
 
+// olderThan reports whether every previous rate is effective before the matching today's rate
+func olderThan(previous, today []rate.Rate) bool {
+	for i := range previous {
+		if i >= len(today) || previous[i].EffectiveDate == "" || today[i].EffectiveDate == "" {
+			continue
+		}
+		if previous[i].EffectiveDate >= today[i].EffectiveDate {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyIfStronger compares today's rate with yesterday's for one pair and sends a notification
+// when JPY got stronger or forceNotify is set. If the providers disagreed on either rate, or
+// only one of them answered, a data-quality warning is sent instead, and a provider switch between the two rates is
+// noted in the alert. When the market is closed today's rate is only a repeat of a rate an
+// earlier check already compared, so no alert is sent unless forced.
+func (r *RateChecker) notifyIfStronger(todayRate, yesterdayRate rate.Rate, marketClosed, forceNotify bool) (*CheckRateResult, error) {
 	result := &CheckRateResult{
+		Base:          todayRate.Base,
+		Target:        todayRate.Target,
 		TodayRate:     todayRate.Value,
 		YesterdayRate: yesterdayRate.Value,
 		IsNotified:    false,
+		Provider:      todayRate.Provider,
+		Derived:       todayRate.Derived,
+		Pivot:         todayRate.Pivot,
+		Spread:        todayRate.Spread,
+		TodayDate:     todayRate.EffectiveDate,
+		YesterdayDate: yesterdayRate.EffectiveDate,
+		MarketClosed:  marketClosed,
+	}
+
+	if todayRate.Divergent || yesterdayRate.Divergent {
+		msg := fmt.Sprintf(
+			"Data Quality Warning! %s/%s: providers disagree by up to %.2f%% (Yesterday %.4f -> Today %.4f). JPY alert skipped.",
+			todayRate.Base,
+			todayRate.Target,
+			max(todayRate.Spread, yesterdayRate.Spread)*100,
+			yesterdayRate.Value,
+			todayRate.Value,
+		)
+		if err := r.Notifier.Notify(msg); err != nil {
+			return nil, fmt.Errorf("failed to notify: %w", err)
+		}
+		result.IsNotified = true
+		result.DataQualityWarning = true
+		return result, nil
 	}
 
-	shouldNotify := forceNotify || (todayRate.Value < yesterdayRate.Value)
+	shouldNotify := forceNotify || (!result.MarketClosed && todayRate.Value.LessThan(yesterdayRate.Value))
 	if !shouldNotify {
 		return result, nil
 	}
 
 	msg := fmt.Sprintf(
 		"JPY Stronger Alert! %s/%s: Yesterday %.4f -> Today %.4f",
-		base,
-		target,
+		todayRate.Base,
+		todayRate.Target,
 		yesterdayRate.Value,
 		todayRate.Value,
 	)
-	if forceNotify && !(todayRate.Value < yesterdayRate.Value) {
+	if forceNotify && (result.MarketClosed || !todayRate.Value.LessThan(yesterdayRate.Value)) {
 		msg = fmt.Sprintf(
 			"Test Notification (forced). %s/%s: Yesterday %.4f -> Today %.4f",
-			base,
-			target,
+			todayRate.Base,
+			todayRate.Target,
 			yesterdayRate.Value,
 			todayRate.Value,
 		)
 	}
 
+	// under failover the two rates may come from different providers, whose quotes differ slightly
+	if todayRate.Provider != "" && yesterdayRate.Provider != "" && todayRate.Provider != yesterdayRate.Provider {
+		msg += fmt.Sprintf(" (provider switched: %s -> %s)", yesterdayRate.Provider, todayRate.Provider)
+	}
+	// a backup provider being down is no reason to message on its own, so it only annotates an alert
+	if todayRate.Unconfirmed || yesterdayRate.Unconfirmed {
+		msg += " (not cross-checked: only one provider answered)"
+	}
+
 	if err := r.Notifier.Notify(msg); err != nil {
 		return nil, fmt.Errorf("failed to notify: %w", err)
 	}
@@ -100,27 +265,138 @@ func (r *RateChecker) CheckRates(ctx context.Context, base, target string, force
 	return result, nil
 }
 
-func (r *RateChecker) saveRates(ctx context.Context, newRate *rate.Rate, rates []*rate.Rate) error {
-	// if the same date already exists, replace it instead of appending
-	replaced := false
-	for i, r := range rates {
-		if r.Date == newRate.Date {
-			rates[i] = newRate
-			replaced = true
+// saveRates adds newRates to the stored histories of their pairs and returns the latest
+// effective date each pair had stored before; a client that can put single dates only looks
+// for stored dates from the pair's earliest new rate on, and reports "" when there are none.
+// A storage.RatePutter stores the new dates without reading the histories, a storage.BatchClient
+// is read and written once for all pairs, and other clients once per pair. When another writer
+// changed a history in the meantime, the read-modify-write is redone on the fresh history.
+func (r *RateChecker) saveRates(ctx context.Context, newRates []*rate.Rate) (map[rate.Pair]string, error) {
+	// the cross-check statistics describe one fetch, not the rate, so they are not stored
+	var pairs []rate.Pair
+	var all []*rate.Rate
+	entries := make(map[rate.Pair][]*rate.Rate)
+	for _, newRate := range newRates {
+		entry := *newRate
+		entry.Spread, entry.Divergent, entry.Unconfirmed = 0, false, false
+		pair := entry.Pair()
+		if _, ok := entries[pair]; !ok {
+			pairs = append(pairs, pair)
+		}
+		entries[pair] = append(entries[pair], &entry)
+		all = append(all, &entry)
+	}
+
+	lastDates := make(map[rate.Pair]string, len(pairs))
+	putter, canPut := r.StorageClient.(storage.RatePutter)
+	ranger, canRange := r.StorageClient.(storage.RangeReader)
+	if canPut && canRange {
+		for _, pair := range pairs {
+			stored, err := ranger.ReadRange(ctx, pair, earliestDate(entries[pair]), maxDate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read rate history: %w", err)
+			}
+			lastDates[pair] = latestDate(stored)
+		}
+		if err := putter.PutRates(ctx, all, r.HistoryLimit); err != nil {
+			return nil, fmt.Errorf("failed to save rates: %w", err)
+		}
+		return lastDates, nil
+	}
+
+	if batch, ok := r.StorageClient.(storage.BatchClient); ok {
+		err := retryOnConflict(func() error {
+			histories, version, err := batch.ReadPairs(ctx, pairs)
+			if err != nil {
+				return fmt.Errorf("failed to read rate history: %w", err)
+			}
+			updates := make(map[rate.Pair][]*rate.Rate, len(pairs))
+			for _, pair := range pairs {
+				lastDates[pair] = latestDate(histories[pair])
+				updates[pair] = mergeRates(histories[pair], entries[pair], r.HistoryLimit)
+			}
+			if err := batch.WritePairs(ctx, updates, version); err != nil {
+				return fmt.Errorf("failed to save rates: %w", err)
+			}
+			return nil
+		})
+		return lastDates, err
+	}
+
+	for _, pair := range pairs {
+		err := retryOnConflict(func() error {
+			rates, version, err := r.StorageClient.Read(ctx, pair)
+			if err != nil {
+				return fmt.Errorf("failed to read rate history: %w", err)
+			}
+			lastDates[pair] = latestDate(rates)
+			if err := r.StorageClient.Write(ctx, pair, mergeRates(rates, entries[pair], r.HistoryLimit), version); err != nil {
+				return fmt.Errorf("failed to save rates: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lastDates, nil
+}
+
+// retryOnConflict runs save again while it fails with a *storage.ConflictError,
+// at most maxSaveAttempts times in total
+func retryOnConflict(save func() error) error {
+	var err error
+	for range maxSaveAttempts {
+		err = save()
+		var conflict *storage.ConflictError
+		if !errors.As(err, &conflict) {
 			break
 		}
 	}
-	if !replaced {
-		rates = append(rates, newRate)
+	return err
+}
+
+// earliestDate returns the earliest effective date in rates, which must not be empty
+func earliestDate(rates []*rate.Rate) string {
+	earliest := rates[0].EffectiveDate
+	for _, rt := range rates[1:] {
+		earliest = min(earliest, rt.EffectiveDate)
 	}
+	return earliest
+}
 
-	// if the json file has more than 7 days' rates, remove the early days' ones
-	if len(rates) >= 7 {
-		rates = rates[len(rates)-7:]
+// latestDate returns the latest effective date in rates, or "" when there are none
+func latestDate(rates []*rate.Rate) string {
+	latest := ""
+	for _, rt := range rates {
+		latest = max(latest, rt.EffectiveDate)
+	}
+	return latest
+}
+
+// mergeRates adds newRates to the history of their pair, keeping it in date order,
+// and keeps at most limit days (all when limit is 0)
+func mergeRates(rates []*rate.Rate, newRates []*rate.Rate, limit int) []*rate.Rate {
+	byDate := make(map[string]int, len(rates))
+	for i, r := range rates {
+		byDate[r.EffectiveDate] = i
+	}
+	for _, newRate := range newRates {
+		// if the same date already exists, replace it instead of appending
+		if i, ok := byDate[newRate.EffectiveDate]; ok {
+			rates[i] = newRate
+			continue
+		}
+		byDate[newRate.EffectiveDate] = len(rates)
+		rates = append(rates, newRate)
 	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].EffectiveDate < rates[j].EffectiveDate
+	})
 
-	if err := r.StorageClient.Write(ctx, rates); err != nil {
-		return fmt.Errorf("failed to save rates: %w", err)
+	// if there are more than limit days' rates, remove the early days' ones
+	if limit > 0 && len(rates) > limit {
+		rates = rates[len(rates)-limit:]
 	}
-	return nil
+	return rates
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"yenup/internal/domain/rate"
+	storageDomain "yenup/internal/domain/storage"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,44 +29,44 @@ func TestCheckRates(t *testing.T) {
 			name:        "success: JPY is stronger",
 			mockRates:   []*rate.Rate{},
 			mockFetcher: []rate.Rate{todayRate, yesterdayRate},
-			expected:    &CheckRateResult{TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, IsNotified: true},
+			expected:    &CheckRateResult{Base: "CAD", Target: "JPY", TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: true},
 		},
 		{
 			name:      "success: JPY is weaker but forceNotify is true",
 			mockRates: []*rate.Rate{},
 			mockFetcher: []rate.Rate{
-				{Date: "2026-03-19", Base: "CAD", Target: "JPY", Value: 113.20},
+				{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.20")},
 				yesterdayRate,
 			},
-			expected:    &CheckRateResult{TodayRate: 113.20, YesterdayRate: yesterdayRate.Value, IsNotified: true},
+			expected:    &CheckRateResult{Base: "CAD", Target: "JPY", TodayRate: rate.MustParseDecimal("113.20"), YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: true},
 			forceNotify: true,
 		},
 		{
 			name:      "success: JPY is weaker, no notification",
 			mockRates: []*rate.Rate{},
 			mockFetcher: []rate.Rate{
-				{Date: "2026-03-19", Base: "CAD", Target: "JPY", Value: 113.20},
+				{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.20")},
 				yesterdayRate,
 			},
-			expected: &CheckRateResult{TodayRate: 113.20, YesterdayRate: yesterdayRate.Value, IsNotified: false},
+			expected: &CheckRateResult{Base: "CAD", Target: "JPY", TodayRate: rate.MustParseDecimal("113.20"), YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: false},
 		},
 		{
 			name:        "success: return 7 rates",
 			mockRates:   testValidRates,
 			mockFetcher: []rate.Rate{todayRate, yesterdayRate},
-			expected:    &CheckRateResult{TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, IsNotified: true},
+			expected:    &CheckRateResult{Base: "CAD", Target: "JPY", TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: true},
 		},
 		{
 			name: "success: replace existing entry for the same date",
 			mockRates: []*rate.Rate{
-				{Date: "2026-03-18", Base: "CAD", Target: "JPY", Value: 111.00},
-				{Date: "2026-03-19", Base: "CAD", Target: "JPY", Value: 999.99},
+				{EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.00")},
+				{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("999.99")},
 			},
 			mockFetcher: []rate.Rate{todayRate, yesterdayRate},
-			expected:    &CheckRateResult{TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, IsNotified: true},
+			expected:    &CheckRateResult{Base: "CAD", Target: "JPY", TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: true},
 			wantWrittenRates: []*rate.Rate{
-				{Date: "2026-03-18", Base: "CAD", Target: "JPY", Value: 111.00},
-				{Date: "2026-03-19", Base: "CAD", Target: "JPY", Value: 110.22},
+				{EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.00")},
+				{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")},
 			},
 		},
 		{
@@ -133,3 +134,346 @@ func TestCheckRates(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckRates_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	storage := &MockStorageClient{rates: []*rate.Rate{}}
+	fetcher := &MockFetcher{rates: []rate.Rate{todayRate, yesterdayRate}}
+	notifier := &MockNotifier{}
+	uc := NewRateChecker(storage, fetcher, notifier)
+
+	result, err := uc.CheckRates(ctx, "CAD", "JPY", false)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+	assert.Nil(t, storage.writtenRates)
+	assert.Empty(t, notifier.msg)
+}
+
+func TestCheckMultipleRates(t *testing.T) {
+	storage := &MockStorageClient{rates: []*rate.Rate{}}
+	fetcher := &MockBatchFetcher{
+		batches: [][]rate.Rate{
+			{todayRate, {EffectiveDate: "2026-03-19", Base: "CAD", Target: "USD", Value: rate.MustParseDecimal("0.74")}},
+			{yesterdayRate, {EffectiveDate: "2026-03-18", Base: "CAD", Target: "USD", Value: rate.MustParseDecimal("0.73")}},
+		},
+	}
+	notifier := &MockNotifier{}
+	uc := NewRateChecker(storage, fetcher, notifier)
+
+	results, err := uc.CheckMultipleRates(context.Background(), "CAD", []rate.Currency{"JPY", "USD"}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]rate.Currency{{"JPY", "USD"}, {"JPY", "USD"}}, fetcher.batchCalls)
+	assert.Equal(t, 0, fetcher.idx, "FetchRate should not be called when batch is available")
+	assert.Equal(t, []*CheckRateResult{
+		{Base: "CAD", Target: "JPY", TodayRate: todayRate.Value, YesterdayRate: yesterdayRate.Value, TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: true},
+		{Base: "CAD", Target: "USD", TodayRate: rate.MustParseDecimal("0.74"), YesterdayRate: rate.MustParseDecimal("0.73"), TodayDate: "2026-03-19", YesterdayDate: "2026-03-18", IsNotified: false},
+	}, results)
+	assert.Len(t, storage.writtenRates, 2)
+	assert.Contains(t, notifier.msg, "CAD/JPY")
+}
+
+func TestCheckMultipleRates_BatchStorage(t *testing.T) {
+	storage := &MockBatchStorageClient{MockStorageClient: MockStorageClient{
+		rates:            []*rate.Rate{},
+		concurrentWrites: []*rate.Rate{{EffectiveDate: "2026-03-19", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("150.12")}},
+	}}
+	fetcher := &MockBatchFetcher{
+		batches: [][]rate.Rate{
+			{todayRate, {EffectiveDate: "2026-03-19", Base: "CAD", Target: "USD", Value: rate.MustParseDecimal("0.74")}},
+			{yesterdayRate, {EffectiveDate: "2026-03-18", Base: "CAD", Target: "USD", Value: rate.MustParseDecimal("0.73")}},
+		},
+	}
+	uc := NewRateChecker(storage, fetcher, &MockNotifier{})
+
+	_, err := uc.CheckMultipleRates(context.Background(), "CAD", []rate.Currency{"JPY", "USD"}, false)
+
+	assert.NoError(t, err)
+	// one read and one write for both pairs, redone once after the concurrent write
+	assert.Equal(t, 2, storage.batchReads)
+	assert.Equal(t, 2, storage.batchWrites)
+	assert.Len(t, storage.writtenRates, 2)
+	assert.Len(t, storage.rates, 3)
+}
+
+func TestCheckRates_PutsSingleDates(t *testing.T) {
+	stored := rate.Rate{EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
+	storage := &MockRangeStorageClient{MockStorageClient: MockStorageClient{rates: []*rate.Rate{&stored}}}
+	uc := NewRateChecker(storage, &MockFetcher{rates: []rate.Rate{todayRate, yesterdayRate}}, &MockNotifier{})
+	uc.HistoryLimit = 0
+
+	result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsNotified)
+	// only the dates from today's rate on are read, and the history is never rewritten
+	assert.Equal(t, [][2]string{{"2026-03-19", maxDate}}, storage.ranges)
+	assert.Zero(t, storage.writeAttempts)
+	if assert.Len(t, storage.putRates, 1) {
+		assert.Equal(t, "2026-03-19", storage.putRates[0].EffectiveDate)
+	}
+}
+
+func TestCheckRates_DataQualityWarning(t *testing.T) {
+	divergentToday := todayRate
+	divergentToday.Spread = 0.05
+	divergentToday.Divergent = true
+
+	storage := &MockStorageClient{rates: []*rate.Rate{}}
+	fetcher := &MockFetcher{rates: []rate.Rate{divergentToday, yesterdayRate}}
+	notifier := &MockNotifier{}
+	uc := NewRateChecker(storage, fetcher, notifier)
+
+	result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+	assert.NoError(t, err)
+	assert.True(t, result.DataQualityWarning)
+	assert.True(t, result.IsNotified)
+	assert.Equal(t, 0.05, result.Spread)
+	assert.Contains(t, notifier.msg, "Data Quality Warning")
+	assert.NotContains(t, notifier.msg, "JPY Stronger Alert")
+	// the cross-check statistics are not stored in the history
+	if assert.Len(t, storage.writtenRates, 1) {
+		assert.False(t, storage.writtenRates[0].Divergent)
+		assert.Zero(t, storage.writtenRates[0].Spread)
+	}
+}
+
+func TestCheckRates_Unconfirmed(t *testing.T) {
+	tests := []struct {
+		name         string
+		today        string
+		wantNotified bool
+	}{
+		{name: "success: noted on the strength alert", today: "110.22", wantNotified: true},
+		{name: "success: no message without an alert", today: "113.00", wantNotified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unconfirmedToday := todayRate
+			unconfirmedToday.Value = rate.MustParseDecimal(tt.today)
+			unconfirmedToday.Unconfirmed = true
+
+			storage := &MockStorageClient{rates: []*rate.Rate{}}
+			fetcher := &MockFetcher{rates: []rate.Rate{unconfirmedToday, yesterdayRate}}
+			notifier := &MockNotifier{}
+			uc := NewRateChecker(storage, fetcher, notifier)
+
+			result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+			assert.NoError(t, err)
+			assert.False(t, result.DataQualityWarning)
+			assert.Equal(t, tt.wantNotified, result.IsNotified)
+			if tt.wantNotified {
+				assert.Contains(t, notifier.msg, "JPY Stronger Alert")
+				assert.Contains(t, notifier.msg, "only one provider answered")
+			} else {
+				assert.Empty(t, notifier.msg)
+			}
+			assert.False(t, storage.writtenRates[0].Unconfirmed)
+		})
+	}
+}
+
+func TestCheckRates_ProviderSwitch(t *testing.T) {
+	tests := []struct {
+		name              string
+		todayProvider     string
+		yesterdayProvider string
+		wantNote          bool
+	}{
+		{name: "same provider", todayProvider: "frankfurter", yesterdayProvider: "frankfurter"},
+		{name: "failover switched provider", todayProvider: "exchangeratesapi", yesterdayProvider: "frankfurter", wantNote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today, yesterday := todayRate, yesterdayRate
+			today.Provider, yesterday.Provider = tt.todayProvider, tt.yesterdayProvider
+			notifier := &MockNotifier{}
+			uc := NewRateChecker(&MockStorageClient{}, &MockFetcher{rates: []rate.Rate{today, yesterday}}, notifier)
+
+			result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+			assert.NoError(t, err)
+			assert.True(t, result.IsNotified)
+			assert.Contains(t, notifier.msg, "JPY Stronger Alert")
+			if tt.wantNote {
+				assert.Contains(t, notifier.msg, "provider switched: frankfurter -> exchangeratesapi")
+			} else {
+				assert.NotContains(t, notifier.msg, "provider switched")
+			}
+		})
+	}
+}
+
+func TestCheckRates_MarketClosed(t *testing.T) {
+	friday := rate.Rate{RequestedDate: "2026-03-21", EffectiveDate: "2026-03-20", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}
+	fridayAgain := friday
+	fridayAgain.RequestedDate = "2026-03-19"
+	thursday := rate.Rate{RequestedDate: "2026-03-18", EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
+	storedFriday := rate.Rate{EffectiveDate: "2026-03-20", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}
+	storedThursday := rate.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
+
+	tests := []struct {
+		name         string
+		stored       []*rate.Rate
+		forceNotify  bool
+		wantClosed   bool
+		wantNotified bool
+		wantMsg      string
+	}{
+		{name: "closed: Friday was already stored, so no alert", stored: []*rate.Rate{&storedThursday, &storedFriday}, wantClosed: true},
+		{name: "closed: forceNotify still notifies", stored: []*rate.Rate{&storedThursday, &storedFriday}, forceNotify: true, wantClosed: true, wantNotified: true, wantMsg: "Test Notification (forced)"},
+		{name: "open: first check after Friday's publication alerts", stored: []*rate.Rate{&storedThursday}, wantNotified: true, wantMsg: "JPY Stronger Alert"},
+		{name: "open: nothing stored yet", stored: []*rate.Rate{}, wantNotified: true, wantMsg: "JPY Stronger Alert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &MockStorageClient{rates: tt.stored}
+			fetcher := &MockFetcher{rates: []rate.Rate{friday, fridayAgain, thursday}}
+			notifier := &MockNotifier{}
+			uc := NewRateChecker(storage, fetcher, notifier)
+
+			result, err := uc.CheckRates(context.Background(), "CAD", "JPY", tt.forceNotify)
+
+			assert.NoError(t, err)
+			// steps back past the provider repeating Friday until an older effective date is found
+			assert.Equal(t, []string{"2026-03-19", "2026-03-18"}, fetcher.dates[1:])
+			assert.Equal(t, tt.wantClosed, result.MarketClosed)
+			assert.Equal(t, "2026-03-20", result.TodayDate)
+			assert.Equal(t, "2026-03-19", result.YesterdayDate)
+			assert.Equal(t, tt.wantNotified, result.IsNotified)
+			if tt.wantNotified {
+				assert.Contains(t, notifier.msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCheckRates_BeforeTodaysPublication(t *testing.T) {
+	// a weekday check that runs before the providers publish gets the previous business day
+	wednesday := rate.Rate{RequestedDate: "2026-03-19", EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}
+	tuesday := rate.Rate{RequestedDate: "2026-03-17", EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
+	storedTuesday := rate.Rate{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
+
+	storage := &MockStorageClient{rates: []*rate.Rate{&storedTuesday}}
+	notifier := &MockNotifier{}
+	uc := NewRateChecker(storage, &MockFetcher{rates: []rate.Rate{wednesday, tuesday}}, notifier)
+
+	result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+	assert.NoError(t, err)
+	assert.False(t, result.MarketClosed)
+	assert.True(t, result.IsNotified)
+	assert.Contains(t, notifier.msg, "JPY Stronger Alert")
+}
+
+func TestCheckRates_NoEarlierEffectiveDate(t *testing.T) {
+	friday := rate.Rate{EffectiveDate: "2026-03-20", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}
+	repeated := make([]rate.Rate, 1+maxLookbackDays)
+	for i := range repeated {
+		repeated[i] = friday
+	}
+
+	storage := &MockStorageClient{rates: []*rate.Rate{}}
+	uc := NewRateChecker(storage, &MockFetcher{rates: repeated}, &MockNotifier{})
+
+	result, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestCheckRates_KeepsOtherPairsHistory(t *testing.T) {
+	usdJPY := &rate.Rate{EffectiveDate: "2026-01-07", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.08")}
+	storage := &MockStorageClient{rates: append([]*rate.Rate{usdJPY}, testValidRates...)}
+	fetcher := &MockFetcher{rates: []rate.Rate{todayRate, yesterdayRate}}
+	uc := NewRateChecker(storage, fetcher, &MockNotifier{})
+
+	_, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+	assert.NoError(t, err)
+	// only the CAD/JPY history is rewritten, trimmed to 7 days
+	assert.Len(t, storage.writtenRates, 7)
+	assert.NotContains(t, storage.writtenRates, usdJPY)
+	assert.Equal(t, todayRate.EffectiveDate, storage.writtenRates[6].EffectiveDate)
+}
+
+func TestCheckRates_RetriesOnConflict(t *testing.T) {
+	// another writer stores an earlier day before each of our writes
+	concurrent := []*rate.Rate{
+		{EffectiveDate: "2026-03-13", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.40")},
+		{EffectiveDate: "2026-03-14", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.50")},
+		{EffectiveDate: "2026-03-15", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.60")},
+		{EffectiveDate: "2026-03-16", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.70")},
+		{EffectiveDate: "2026-03-17", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.80")},
+	}
+
+	tests := []struct {
+		name             string
+		concurrentWrites int
+		wantAttempts     int
+		wantErr          bool
+	}{
+		{name: "success: no conflict", concurrentWrites: 0, wantAttempts: 1},
+		{name: "success: retried after concurrent updates", concurrentWrites: 2, wantAttempts: 3},
+		{name: "error: conflicts on every attempt", concurrentWrites: maxSaveAttempts, wantAttempts: maxSaveAttempts, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &MockStorageClient{rates: []*rate.Rate{}}
+			storage.concurrentWrites = concurrent[:tt.concurrentWrites]
+			fetcher := &MockFetcher{rates: []rate.Rate{todayRate, yesterdayRate}}
+			uc := NewRateChecker(storage, fetcher, &MockNotifier{})
+
+			_, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+			assert.Equal(t, tt.wantAttempts, storage.writeAttempts)
+			if tt.wantErr {
+				var conflict *storageDomain.ConflictError
+				assert.ErrorAs(t, err, &conflict)
+				return
+			}
+			assert.NoError(t, err)
+			// the concurrent writer's updates survive next to ours
+			assert.Len(t, storage.writtenRates, tt.concurrentWrites+1)
+			for _, r := range concurrent[:tt.concurrentWrites] {
+				assert.Contains(t, storage.writtenRates, r)
+			}
+			assert.Equal(t, todayRate.EffectiveDate, storage.writtenRates[tt.concurrentWrites].EffectiveDate)
+		})
+	}
+}
+
+func TestCheckRates_HistoryLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		wantWritten int
+	}{
+		{name: "default keeps 7 days", limit: DefaultHistoryLimit, wantWritten: 7},
+		{name: "larger limit keeps more", limit: 30, wantWritten: len(testValidRates) + 1},
+		{name: "zero keeps everything", limit: 0, wantWritten: len(testValidRates) + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &MockStorageClient{rates: append([]*rate.Rate{}, testValidRates...)}
+			fetcher := &MockFetcher{rates: []rate.Rate{todayRate, yesterdayRate}}
+			uc := NewRateChecker(storage, fetcher, &MockNotifier{})
+			uc.HistoryLimit = tt.limit
+
+			_, err := uc.CheckRates(context.Background(), "CAD", "JPY", false)
+
+			assert.NoError(t, err)
+			assert.Len(t, storage.writtenRates, tt.wantWritten)
+		})
+	}
+}
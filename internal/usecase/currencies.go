@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"yenup/internal/domain/rate"
+)
+
+// ErrCurrencyListUnsupported means the rate providers cannot report their currencies
+var ErrCurrencyListUnsupported = errors.New("active rate provider cannot list its currencies")
+
+type CurrencyUsecase interface {
+	ListCurrencies(ctx context.Context) ([]rate.Currency, error)
+	ValidateCurrencies(ctx context.Context, currencies ...rate.Currency) error
+}
+
+type CurrencyChecker struct {
+	Lister rate.CurrencyLister // nil when the active provider cannot list currencies
+}
+
+// NewCurrencyChecker creates a new CurrencyChecker; lister may be nil.
+func NewCurrencyChecker(lister rate.CurrencyLister) *CurrencyChecker {
+	return &CurrencyChecker{
+		Lister: lister,
+	}
+}
+
+// ListCurrencies returns the currencies the active rate provider supports.
+func (c *CurrencyChecker) ListCurrencies(ctx context.Context) ([]rate.Currency, error) {
+	if c.Lister == nil {
+		return nil, ErrCurrencyListUnsupported
+	}
+	currencies, err := c.Lister.ListCurrencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	return currencies, nil
+}
+
+// ValidateCurrencies returns rate.ErrUnsupportedCurrency for the first currency the provider does not list.
+// When the list is unavailable the currencies are let through, so the rate check itself reports
+// any provider problem.
+func (c *CurrencyChecker) ValidateCurrencies(ctx context.Context, currencies ...rate.Currency) error {
+	if c.Lister == nil {
+		return nil
+	}
+	supported, err := c.Lister.ListCurrencies(ctx)
+	if err != nil {
+		log.Printf("skipping currency validation: %v", err)
+		return nil
+	}
+	for _, currency := range currencies {
+		if _, found := slices.BinarySearch(supported, currency); !found {
+			return fmt.Errorf("%w: %s is not offered by the rate provider", rate.ErrUnsupportedCurrency, currency)
+		}
+	}
+	return nil
+}
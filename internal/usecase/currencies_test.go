@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCurrencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		lister     rate.CurrencyLister
+		currencies []rate.Currency
+		wantErr    error
+	}{
+		{
+			name:       "success: all offered",
+			lister:     &MockCurrencyLister{currencies: []rate.Currency{"CAD", "JPY", "USD"}},
+			currencies: []rate.Currency{"CAD", "JPY"},
+		},
+		{
+			name:       "success: provider cannot list",
+			currencies: []rate.Currency{"CAD", "JPY"},
+		},
+		{
+			name:       "success: list unavailable lets the check through",
+			lister:     &MockCurrencyLister{err: rate.ErrProviderUnavailable},
+			currencies: []rate.Currency{"CAD", "JPY"},
+		},
+		{
+			name:       "error: currency not offered",
+			lister:     &MockCurrencyLister{currencies: []rate.Currency{"CAD", "JPY", "USD"}},
+			currencies: []rate.Currency{"CAD", "XAU"},
+			wantErr:    rate.ErrUnsupportedCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewCurrencyChecker(tt.lister)
+
+			err := uc.ValidateCurrencies(context.Background(), tt.currencies...)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListCurrencies_Unsupported(t *testing.T) {
+	uc := NewCurrencyChecker(nil)
+
+	_, err := uc.ListCurrencies(context.Background())
+
+	assert.ErrorIs(t, err, ErrCurrencyListUnsupported)
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+
+	"yenup/internal/domain/rate"
+)
+
+type HealthUsecase interface {
+	CheckHealth(ctx context.Context) *HealthResult
+}
+
+// HealthResult is the health of the service and each rate provider
+type HealthResult struct {
+	Healthy   bool // false when every provider is tripped
+	Providers []rate.ProviderStatus
+}
+
+type HealthChecker struct {
+	Reporters []rate.StatusReporter
+}
+
+// NewHealthChecker creates a new HealthChecker reporting on the given providers.
+func NewHealthChecker(reporters ...rate.StatusReporter) *HealthChecker {
+	return &HealthChecker{
+		Reporters: reporters,
+	}
+}
+
+// CheckHealth collects the status of every provider.
+func (h *HealthChecker) CheckHealth(ctx context.Context) *HealthResult {
+	result := &HealthResult{
+		Healthy:   len(h.Reporters) == 0,
+		Providers: make([]rate.ProviderStatus, 0, len(h.Reporters)),
+	}
+	for _, r := range h.Reporters {
+		status := r.Status()
+		if status.State != rate.BreakerOpen {
+			result.Healthy = true
+		}
+		result.Providers = append(result.Providers, status)
+	}
+	return result
+}
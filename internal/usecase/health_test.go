@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"yenup/internal/domain/rate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		states      []string
+		wantHealthy bool
+	}{
+		{name: "healthy: no providers tracked", states: nil, wantHealthy: true},
+		{name: "healthy: all closed", states: []string{rate.BreakerClosed, rate.BreakerClosed}, wantHealthy: true},
+		{name: "healthy: one provider still usable", states: []string{rate.BreakerOpen, rate.BreakerHalfOpen}, wantHealthy: true},
+		{name: "unhealthy: all open", states: []string{rate.BreakerOpen, rate.BreakerOpen}, wantHealthy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reporters []rate.StatusReporter
+			for _, state := range tt.states {
+				reporters = append(reporters, &MockStatusReporter{status: rate.ProviderStatus{Name: "p", State: state}})
+			}
+			uc := NewHealthChecker(reporters...)
+
+			result := uc.CheckHealth(context.Background())
+
+			assert.Equal(t, tt.wantHealthy, result.Healthy)
+			assert.Len(t, result.Providers, len(tt.states))
+		})
+	}
+}
@@ -5,26 +5,82 @@ import (
 	"fmt"
 
 	"yenup/internal/domain/rate"
+	"yenup/internal/domain/storage"
 )
 
+// MockStorageClient is an in-memory storage.Client. Every successful write bumps its
+// version, and concurrentWrites simulate other writers updating the history between
+// a read and the following write, which makes that write fail with a conflict.
 type MockStorageClient struct {
-	rates        []*rate.Rate
-	readErr      error
-	writeErr     error
-	writtenRates []*rate.Rate
+	rates            []*rate.Rate
+	readErr          error
+	writeErr         error
+	writtenRates     []*rate.Rate
+	version          storage.Version
+	concurrentWrites []*rate.Rate // applied one per write attempt, before the version check
+	writeAttempts    int
 }
 
 // ----------------------------------------------------------------------------------------------
 // weekly_report_test helpers
 // ----------------------------------------------------------------------------------------------
 
-func (m *MockStorageClient) Read(ctx context.Context) ([]*rate.Rate, error) {
-	return m.rates, m.readErr
+// Read returns the stored rates of pair and the current version
+func (m *MockStorageClient) Read(ctx context.Context, pair rate.Pair) ([]*rate.Rate, storage.Version, error) {
+	if m.readErr != nil {
+		return nil, 0, m.readErr
+	}
+	rates := []*rate.Rate{}
+	for _, r := range m.rates {
+		if r.Pair() == pair {
+			rates = append(rates, r)
+		}
+	}
+	return rates, m.version, nil
 }
 
-func (m *MockStorageClient) Write(ctx context.Context, rates []*rate.Rate) error {
-	m.writtenRates = rates
-	return m.writeErr
+// Write replaces the history of pair and records the written rates; writes for several
+// pairs are appended in call order
+func (m *MockStorageClient) Write(ctx context.Context, pair rate.Pair, rates []*rate.Rate, version storage.Version) error {
+	m.writeAttempts++
+	if len(m.concurrentWrites) > 0 {
+		m.rates = append(m.rates, m.concurrentWrites[0])
+		m.concurrentWrites = m.concurrentWrites[1:]
+		m.version++
+	}
+	if version != m.version {
+		return &storage.ConflictError{Pair: pair, Version: version}
+	}
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+
+	kept := make([]*rate.Rate, 0, len(m.rates)+len(rates))
+	for _, r := range m.rates {
+		if r.Pair() != pair {
+			kept = append(kept, r)
+		}
+	}
+	m.rates = append(kept, rates...)
+	m.version++
+	m.writtenRates = append(m.writtenRates, rates...)
+	return nil
+}
+
+// Pairs returns the pairs of the stored rates in order of first appearance
+func (m *MockStorageClient) Pairs(ctx context.Context) ([]rate.Pair, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	var pairs []rate.Pair
+	seen := make(map[rate.Pair]bool)
+	for _, r := range m.rates {
+		if !seen[r.Pair()] {
+			seen[r.Pair()] = true
+			pairs = append(pairs, r.Pair())
+		}
+	}
+	return pairs, nil
 }
 
 type MockNotifier struct {
@@ -38,43 +94,47 @@ func (m *MockNotifier) Notify(message string) error {
 }
 
 var testValidRates = []*rate.Rate{
-	{Date: "2026-01-01", Base: "CAD", Target: "JPY", Value: 113.2207},
-	{Date: "2026-01-02", Base: "CAD", Target: "JPY", Value: 112.5783},
-	{Date: "2026-01-03", Base: "CAD", Target: "JPY", Value: 113.3475},
-	{Date: "2026-01-04", Base: "CAD", Target: "JPY", Value: 110.4845},
-	{Date: "2026-01-05", Base: "CAD", Target: "JPY", Value: 115.0950},
-	{Date: "2026-01-06", Base: "CAD", Target: "JPY", Value: 114.2859},
-	{Date: "2026-01-07", Base: "CAD", Target: "JPY", Value: 111.6880},
+	{EffectiveDate: "2026-01-01", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.2207")},
+	{EffectiveDate: "2026-01-02", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.5783")},
+	{EffectiveDate: "2026-01-03", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.3475")},
+	{EffectiveDate: "2026-01-04", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.4845")},
+	{EffectiveDate: "2026-01-05", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("115.0950")},
+	{EffectiveDate: "2026-01-06", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("114.2859")},
+	{EffectiveDate: "2026-01-07", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.6880")},
 }
 
 var testDuplicatedDate = []*rate.Rate{
-	{Date: "2026-01-01", Base: "CAD", Target: "JPY", Value: 113.2207},
-	{Date: "2026-01-07", Base: "CAD", Target: "JPY", Value: 111.6880},
-	{Date: "2026-01-07", Base: "CAD", Target: "JPY", Value: 110.6090},
-}
-var testInconsistentBase = []*rate.Rate{
-	{Date: "2026-01-01", Base: "CAD", Target: "JPY", Value: 113.2207},
-	{Date: "2026-01-08", Base: "USD", Target: "JPY", Value: 113.8581},
+	{EffectiveDate: "2026-01-01", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.2207")},
+	{EffectiveDate: "2026-01-07", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("111.6880")},
+	{EffectiveDate: "2026-01-07", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.6090")},
 }
-var testInconsistentTarget = []*rate.Rate{
-	{Date: "2026-01-01", Base: "CAD", Target: "JPY", Value: 113.2207},
-	{Date: "2026-01-09", Base: "CAD", Target: "USD", Value: 112.0795},
+var testMultiplePairs = []*rate.Rate{
+	{EffectiveDate: "2026-01-01", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("113.2207")},
+	{EffectiveDate: "2026-01-01", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("156.8581")},
+	{EffectiveDate: "2026-01-02", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.5783")},
+	{EffectiveDate: "2026-01-02", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.0795")},
 }
 
 // ----------------------------------------------------------------------------------------------
 // check_rate_test helpers
 // ----------------------------------------------------------------------------------------------
 
-var todayRate = rate.Rate{Date: "2026-03-19", Base: "CAD", Target: "JPY", Value: 110.22}
-var yesterdayRate = rate.Rate{Date: "2026-03-18", Base: "CAD", Target: "JPY", Value: 112.50}
+var todayRate = rate.Rate{EffectiveDate: "2026-03-19", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("110.22")}
+var yesterdayRate = rate.Rate{EffectiveDate: "2026-03-18", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("112.50")}
 
 type MockFetcher struct {
 	rates []rate.Rate
 	idx   int
 	err   error
+	dates []string // requested dates, in call order
 }
 
-func (m *MockFetcher) FetchRate(date, base, target string) (rate.Rate, error) {
+func (m *MockFetcher) FetchRate(ctx context.Context, date string, base, target rate.Currency) (rate.Rate, error) {
+	// honour cancellation like the real fetchers do
+	if err := ctx.Err(); err != nil {
+		return rate.Rate{}, err
+	}
+	m.dates = append(m.dates, date)
 	// return error if configured
 	if m.err != nil {
 		return rate.Rate{}, m.err
@@ -87,3 +147,129 @@ func (m *MockFetcher) FetchRate(date, base, target string) (rate.Rate, error) {
 	m.idx++
 	return r, nil
 }
+
+// MockBatchFetcher returns one configured batch per FetchRates call and records the targets it was asked for
+type MockBatchFetcher struct {
+	MockFetcher
+	batches    [][]rate.Rate
+	batchCalls [][]rate.Currency
+}
+
+func (m *MockBatchFetcher) FetchRates(ctx context.Context, date string, base rate.Currency, targets []rate.Currency) ([]rate.Rate, error) {
+	m.batchCalls = append(m.batchCalls, targets)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.batchCalls) > len(m.batches) {
+		return nil, fmt.Errorf("mock batch fetcher: no rates configured for call %d", len(m.batchCalls))
+	}
+	return m.batches[len(m.batchCalls)-1], nil
+}
+
+// MockBatchStorageClient adds storage.BatchClient to MockStorageClient and counts the batch calls
+type MockBatchStorageClient struct {
+	MockStorageClient
+	batchReads  int
+	batchWrites int
+}
+
+func (m *MockBatchStorageClient) ReadPairs(ctx context.Context, pairs []rate.Pair) (map[rate.Pair][]*rate.Rate, storage.Version, error) {
+	m.batchReads++
+	histories := make(map[rate.Pair][]*rate.Rate, len(pairs))
+	for _, pair := range pairs {
+		rates, _, err := m.Read(ctx, pair)
+		if err != nil {
+			return nil, 0, err
+		}
+		histories[pair] = rates
+	}
+	return histories, m.version, nil
+}
+
+// WritePairs writes each pair through Write; only the first one is checked against version
+func (m *MockBatchStorageClient) WritePairs(ctx context.Context, histories map[rate.Pair][]*rate.Rate, version storage.Version) error {
+	m.batchWrites++
+	for pair, rates := range histories {
+		if err := m.Write(ctx, pair, rates, version); err != nil {
+			return err
+		}
+		version = m.version
+	}
+	return nil
+}
+
+// MockRangeStorageClient adds storage.RangeReader and storage.RatePutter to MockStorageClient
+// and records the ranges read and the rates put
+type MockRangeStorageClient struct {
+	MockStorageClient
+	ranges   [][2]string
+	putRates []*rate.Rate
+	putLimit int
+}
+
+func (m *MockRangeStorageClient) ReadRange(ctx context.Context, pair rate.Pair, from, to string) ([]*rate.Rate, error) {
+	m.ranges = append(m.ranges, [2]string{from, to})
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	rates := []*rate.Rate{}
+	for _, r := range m.rates {
+		if r.Pair() == pair && r.EffectiveDate >= from && r.EffectiveDate <= to {
+			rates = append(rates, r)
+		}
+	}
+	return rates, nil
+}
+
+func (m *MockRangeStorageClient) PutRates(ctx context.Context, rates []*rate.Rate, limit int) error {
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	m.putRates = append(m.putRates, rates...)
+	m.putLimit = limit
+	return nil
+}
+
+// ----------------------------------------------------------------------------------------------
+// backfill_test helpers
+// ----------------------------------------------------------------------------------------------
+
+// MockRangeFetcher returns a configured series and records the range it was asked for
+type MockRangeFetcher struct {
+	MockFetcher
+	series   []rate.Rate
+	from, to string
+}
+
+func (m *MockRangeFetcher) FetchRange(ctx context.Context, from, to string, base, target rate.Currency) ([]rate.Rate, error) {
+	m.from, m.to = from, to
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.series, nil
+}
+
+// ----------------------------------------------------------------------------------------------
+// health_test helpers
+// ----------------------------------------------------------------------------------------------
+
+type MockStatusReporter struct {
+	status rate.ProviderStatus
+}
+
+func (m *MockStatusReporter) Status() rate.ProviderStatus {
+	return m.status
+}
+
+// ----------------------------------------------------------------------------------------------
+// currencies_test helpers
+// ----------------------------------------------------------------------------------------------
+
+type MockCurrencyLister struct {
+	currencies []rate.Currency
+	err        error
+}
+
+func (m *MockCurrencyLister) ListCurrencies(ctx context.Context) ([]rate.Currency, error) {
+	return m.currencies, m.err
+}
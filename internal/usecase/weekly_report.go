@@ -4,10 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"yenup/internal/domain/notifier"
+	"yenup/internal/domain/rate"
 	"yenup/internal/domain/storage"
 )
 
+// reportDays is how many days, up to today, the weekly report covers
+const reportDays = 7
+
 type WeeklyReportUsecase interface {
 	GenerateReport(ctx context.Context) error
 }
@@ -15,6 +23,7 @@ type WeeklyReportUsecase interface {
 type WeeklyReporter struct {
 	StorageClient storage.Client
 	Notifier      notifier.Notifier
+	now           func() time.Time
 }
 
 // NewWeeklyReporter creates a new WeeklyReporter with the given storage client and notifier.
@@ -22,54 +31,105 @@ func NewWeeklyReporter(storageClient storage.Client, notifier notifier.Notifier)
 	return &WeeklyReporter{
 		StorageClient: storageClient,
 		Notifier:      notifier,
+		now:           time.Now,
 	}
 }
 
-// GenerateReport reads rates from GCS, calucurates weekly summery, and notifies via Slack.
+// GenerateReport reads each pair's rates from storage, calucurates weekly summery, and notifies via Slack.
 func (w *WeeklyReporter) GenerateReport(ctx context.Context) error {
-	// read rates
-	rates, err := w.StorageClient.Read(ctx)
+	pairs, err := w.StorageClient.Pairs(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read rates: %w", err)
+		return fmt.Errorf("failed to list pairs: %w", err)
+	}
+
+	today := w.now()
+	from, to := today.AddDate(0, 0, 1-reportDays).Format("2006-01-02"), today.Format("2006-01-02")
+
+	var lines []string
+	for _, pair := range pairs {
+		// read rates
+		rates, err := w.readWeek(ctx, pair, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to read rates: %w", err)
+		}
+		if len(rates) == 0 {
+			continue
+		}
+
+		line, err := summarize(pair, rates)
+		if err != nil {
+			// one corrupted history should not hold back the report of the other pairs
+			log.Printf("skipping %s in the weekly report: %v", pair, err)
+			continue
+		}
+		lines = append(lines, line)
 	}
 	// validate rates
-	if len(rates) == 0 {
+	if len(lines) == 0 {
 		return errors.New("no rates found")
 	}
 
-	var total float64
+	// Notify
+	msg := "This week report.\n" + strings.Join(lines, "\n")
+
+	if err := w.Notifier.Notify(msg); err != nil {
+		return fmt.Errorf("failed to notify: %w", err)
+	}
+
+	return nil
+}
+
+// readWeek returns the rates of pair effective between from and to. A storage.RangeReader
+// is asked for that range only, so long histories are not loaded in full.
+func (w *WeeklyReporter) readWeek(ctx context.Context, pair rate.Pair, from, to string) ([]*rate.Rate, error) {
+	if ranger, ok := w.StorageClient.(storage.RangeReader); ok {
+		return ranger.ReadRange(ctx, pair, from, to)
+	}
+
+	rates, _, err := w.StorageClient.Read(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+	week := make([]*rate.Rate, 0, reportDays)
+	for _, r := range rates {
+		if r.EffectiveDate >= from && r.EffectiveDate <= to {
+			week = append(week, r)
+		}
+	}
+	return week, nil
+}
+
+// summarize calcurates max, min and average of one pair's rates
+func summarize(pair rate.Pair, rates []*rate.Rate) (string, error) {
+	var total rate.Decimal
 	dateMap := make(map[string]bool)
-	baseBase := rates[0].Base
-	baseTarget := rates[0].Target
 	max := rates[0].Value
 	min := rates[0].Value
-	// calcurate max, min, average
 	for _, r := range rates {
-		if dateMap[r.Date] {
-			return errors.New("duplicate dates")
+		if dateMap[r.EffectiveDate] {
+			return "", fmt.Errorf("duplicate dates in %s", pair)
 		}
-		dateMap[r.Date] = true
-		if r.Base != baseBase || r.Target != baseTarget {
-			return errors.New("inconsistent base/target")
+		dateMap[r.EffectiveDate] = true
+		if r.Pair() != pair {
+			return "", fmt.Errorf("inconsistent base/target in %s: %s", pair, r.Pair())
 		}
 
-		if max < r.Value {
+		if max.LessThan(r.Value) {
 			max = r.Value
 		}
-		if min > r.Value {
+		if min.GreaterThan(r.Value) {
 			min = r.Value
 		}
 
-		total += r.Value
+		var err error
+		if total, err = total.Add(r.Value); err != nil {
+			return "", fmt.Errorf("failed to calculate total of %s: %w", pair, err)
+		}
 	}
-	average := total / float64(len(rates))
-
-	// Notify
-	msg := fmt.Sprintf("This week report. Average: %.2f, Max: %.2f, Min: %.2f", average, max, min)
-
-	if err := w.Notifier.Notify(msg); err != nil {
-		return fmt.Errorf("failed to notify: %w", err)
+	average, err := total.Div(rate.NewDecimalFromInt(int64(len(rates))), rate.DefaultPrecision)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate average: %w", err)
 	}
 
-	return nil
+	return fmt.Sprintf("%s Average: %.2f, Max: %.2f, Min: %.2f", pair, average, max, min), nil
 }
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"yenup/internal/domain/rate"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// testReportDay is the last day of the week covered by testValidRates
+var testReportDay = time.Date(2026, 1, 7, 9, 0, 0, 0, time.Local)
+
 func TestGenerateReport(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -47,18 +51,11 @@ func TestGenerateReport(t *testing.T) {
 			wantErr:       true,
 		},
 		{
-			name:          "err: inconsistent base",
-			mockRates:     testInconsistentBase,
-			mockReadErr:   nil,
-			mockNotifyErr: nil,
-			wantErr:       true,
-		},
-		{
-			name:          "err: inconsistent target",
-			mockRates:     testInconsistentTarget,
+			name:          "success: one line per pair",
+			mockRates:     testMultiplePairs,
 			mockReadErr:   nil,
 			mockNotifyErr: nil,
-			wantErr:       true,
+			wantErr:       false,
 		},
 		{
 			name:          "err: fail to notify",
@@ -79,6 +76,7 @@ func TestGenerateReport(t *testing.T) {
 			}
 			notifier := &MockNotifier{err: tt.mockNotifyErr}
 			uc := NewWeeklyReporter(storage, notifier)
+			uc.now = func() time.Time { return testReportDay }
 			err := uc.GenerateReport(ctx)
 
 			if tt.wantErr {
@@ -91,8 +89,67 @@ func TestGenerateReport(t *testing.T) {
 				assert.Contains(t, notifier.msg, "Average")
 				assert.Contains(t, notifier.msg, "Max")
 				assert.Contains(t, notifier.msg, "Min")
+				for _, r := range tt.mockRates {
+					assert.Contains(t, notifier.msg, r.Pair().String())
+				}
 			}
 		})
 	}
 
 }
+
+func TestGenerateReport_PairsSummarizedSeparately(t *testing.T) {
+	storage := &MockStorageClient{rates: testMultiplePairs}
+	notifier := &MockNotifier{}
+	uc := NewWeeklyReporter(storage, notifier)
+	uc.now = func() time.Time { return testReportDay }
+
+	err := uc.GenerateReport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Contains(t, notifier.msg, "CAD/JPY Average: 112.90, Max: 113.22, Min: 112.58")
+	assert.Contains(t, notifier.msg, "USD/JPY Average: 156.97, Max: 157.08, Min: 156.86")
+}
+
+func TestGenerateReport_SkipsInvalidPair(t *testing.T) {
+	usdJPY := &rate.Rate{EffectiveDate: "2026-01-07", Base: "USD", Target: "JPY", Value: rate.MustParseDecimal("157.0795")}
+	storage := &MockStorageClient{rates: append([]*rate.Rate{usdJPY}, testDuplicatedDate...)}
+	notifier := &MockNotifier{}
+	uc := NewWeeklyReporter(storage, notifier)
+	uc.now = func() time.Time { return testReportDay }
+
+	err := uc.GenerateReport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Contains(t, notifier.msg, "USD/JPY Average: 157.08")
+	assert.NotContains(t, notifier.msg, "CAD/JPY")
+}
+
+func TestGenerateReport_LatestWeekOnly(t *testing.T) {
+	// an older, much higher rate is kept in a long history but is not part of this week
+	old := &rate.Rate{EffectiveDate: "2025-12-01", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("130.00")}
+	storage := &MockStorageClient{rates: append([]*rate.Rate{old}, testValidRates...)}
+	notifier := &MockNotifier{}
+	uc := NewWeeklyReporter(storage, notifier)
+	uc.now = func() time.Time { return testReportDay }
+
+	err := uc.GenerateReport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Contains(t, notifier.msg, "Max: 115.10")
+	assert.NotContains(t, notifier.msg, "130.00")
+}
+
+func TestGenerateReport_ReadsOnlyTheWeek(t *testing.T) {
+	old := &rate.Rate{EffectiveDate: "2025-12-01", Base: "CAD", Target: "JPY", Value: rate.MustParseDecimal("130.00")}
+	storage := &MockRangeStorageClient{MockStorageClient: MockStorageClient{rates: append([]*rate.Rate{old}, testValidRates...)}}
+	notifier := &MockNotifier{}
+	uc := NewWeeklyReporter(storage, notifier)
+	uc.now = func() time.Time { return testReportDay }
+
+	err := uc.GenerateReport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]string{{"2026-01-01", "2026-01-07"}}, storage.ranges)
+	assert.Contains(t, notifier.msg, "Max: 115.10")
+}